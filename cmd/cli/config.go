@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+const configUsage = `Usage:
+  go run cmd/cli/main.go config show [--config=config.yaml] [--database-dsn=...]
+  go run cmd/cli/main.go config validate [--config=config.yaml] [--database-dsn=...]
+  go run cmd/cli/main.go config docs`
+
+// errInvalidConfig is returned by config validate once the problems are printed.
+var errInvalidConfig = errors.New("invalid config")
+
+// runConfig inspects the config the server would load with the same environment,
+// files and flags. It runs before the config is loaded, validate reports the
+// problems of an invalid one instead of failing.
+func runConfig(log logger.Logger, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "show":
+		cfg, err := config.NewConfig(log, args[1:]...)
+		if err != nil {
+			return err
+		}
+		return showConfig(out, cfg)
+	case "validate":
+		cfg, err := config.NewConfig(log, args[1:]...)
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintln(out, "Invalid config:")
+			for _, msg := range config.Explain(err) {
+				fmt.Fprintln(out, "  - "+msg)
+			}
+			return errInvalidConfig
+		}
+		if cfg.App.Env == "" {
+			fmt.Fprintln(out, "Config is valid (no APP_ENV profile)")
+			return nil
+		}
+		fmt.Fprintf(out, "Config is valid (APP_ENV=%s)\n", cfg.App.Env)
+		return nil
+	case "docs":
+		return configDocs(out)
+	default:
+		return errors.New(configUsage)
+	}
+}
+
+// showConfig prints the effective settings with what set them, sensitive values
+// are redacted.
+func showConfig(out io.Writer, cfg *config.Config) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tENV\tVALUE\tSOURCE")
+	for _, s := range cfg.Describe() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Key, s.Env, s.Value, s.Source)
+	}
+	return w.Flush()
+}
+
+// configDocs prints a markdown table of every setting.
+func configDocs(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Variable | Config key | Default | Constraints | Profile defaults | Reload |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+
+	for _, s := range config.Docs() {
+		profiles := make([]string, 0, len(s.Profiles))
+		for name, val := range s.Profiles {
+			profiles = append(profiles, fmt.Sprintf("%s: `%s`", name, val))
+		}
+		sort.Strings(profiles)
+
+		reload := "live"
+		if s.Restart {
+			reload = "restart"
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s | %s | %s |\n",
+			s.Env, s.Key, code(s.Value), code(s.Constraints), strings.Join(profiles, ", "), reload)
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// code formats val as inline code, empty values are left blank.
+func code(val string) string {
+	if val == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(val, "|", `\|`) + "`"
+}
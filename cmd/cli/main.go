@@ -1,11 +1,10 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"os"
 
-	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
-	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
-	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/seeder"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
 )
 
@@ -13,32 +12,34 @@ func main() {
 	log := logger.NewZerologLogger("info", os.Stderr)
 
 	if len(os.Args) < 2 {
-		log.Info("Usage: go run cmd/cli/main.go seed")
+		log.Info("Usage: go run cmd/cli/main.go seed|secrets|config [--config=config.yaml] [--database-dsn=...]")
 		os.Exit(1)
 	}
 
 	cmd := os.Args[1]
 
-	cfg, err := config.NewConfig(log)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-
 	switch cmd {
-	case "seed":
-		db, err := database.NewDatabase(&database.Opts{
-			Config: cfg.Database,
-			Logger: log,
-		})
-		if err != nil {
+	case "secrets":
+		if err := runSecrets(os.Args[2:]); err != nil {
 			log.Fatal(err.Error())
 		}
-		defer db.Close()
-
-		err = seeder.RunAll(&seeder.Opts{
-			DB:  db.DB(),
-			Log: log,
-		})
+	case "config":
+		err := runConfig(log, os.Args[2:], os.Stdout)
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			os.Exit(0) // the flags were printed
+		case errors.Is(err, errInvalidConfig):
+			os.Exit(1) // the problems were printed
+		case err != nil:
+			log.Fatal(err.Error())
+		}
+	case "seed":
+		// The components are stopped by runSeed, log.Fatal exits without running
+		// deferred calls.
+		err := runSeed(log, os.Args[2:], os.Stdout)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0) // the flags were printed
+		}
 		if err != nil {
 			log.Fatal(err.Error())
 		}
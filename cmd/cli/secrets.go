@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/secrets"
+)
+
+const secretsUsage = `Usage:
+  go run cmd/cli/main.go secrets keygen
+  SECRETS_KEY=... go run cmd/cli/main.go secrets encrypt <secrets.json> <secrets.enc>`
+
+// runSecrets manages the encrypted secrets file. It runs before the config is
+// loaded, the config may reference secrets that aren't encrypted yet.
+func runSecrets(args []string) error {
+	if len(args) == 0 {
+		return errors.New(secretsUsage)
+	}
+
+	switch args[0] {
+	case "keygen":
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	case "encrypt":
+		if len(args) != 3 {
+			return errors.New(secretsUsage)
+		}
+		return encryptSecrets(os.Getenv("SECRETS_KEY"), args[1], args[2])
+	default:
+		return errors.New(secretsUsage)
+	}
+}
+
+// encryptSecrets encrypts a JSON object of secret names and values.
+func encryptSecrets(key, in, out string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	plain := map[string]string{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return fmt.Errorf("%s must be a JSON object of secret names and values: %w", in, err)
+	}
+
+	encrypted, err := secrets.Encrypt(key, plain)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, encrypted, 0600)
+}
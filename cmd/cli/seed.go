@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/app"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/seeder"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+const seedUsage = `Usage:
+  go run cmd/cli/main.go seed [--force] [--only=SeedUsers,...] [--except=SeedUsers,...] [--ignore-env] [--count=10] [--seed=0] [--config=config.yaml] [--database-dsn=...]
+  go run cmd/cli/main.go seed status [--only=...] [--except=...] [--config=config.yaml] [--database-dsn=...]`
+
+// runSeed runs the seeders that haven't run yet and are allowed in the APP_ENV,
+// or prints their status. The flags that aren't seed flags are config flags.
+func runSeed(log logger.Logger, args []string, out io.Writer) error {
+	status := len(args) > 0 && args[0] == "status"
+	if status {
+		args = args[1:]
+	}
+
+	var force, ignoreEnv bool
+	var only, except string
+	var count int
+	var seed uint64
+	set := flag.NewFlagSet("seed", flag.ContinueOnError)
+	set.BoolVar(&force, "force", false, "run the seeders even if they already ran")
+	set.StringVar(&only, "only", "", "comma separated seeders to run")
+	set.StringVar(&except, "except", "", "comma separated seeders to leave out")
+	set.BoolVar(&ignoreEnv, "ignore-env", false, "run the seeders not allowed in the APP_ENV, e.g development fixtures in production")
+	set.IntVar(&count, "count", seeder.DefaultCount, "number of rows created by the seeders generating fake data")
+	set.Uint64Var(&seed, "seed", 0, "seed of the fake data, the same seed generates the same data")
+	set.SetOutput(io.Discard)
+
+	seedArgs, configArgs := splitArgs(set, args)
+	if err := set.Parse(seedArgs); err != nil {
+		return err
+	}
+	if set.NArg() > 0 {
+		return errors.New(seedUsage)
+	}
+	if count <= 0 {
+		return errors.New("--count must be positive")
+	}
+
+	cfg, err := config.NewConfig(log, configArgs...)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, seedUsage)
+		set.SetOutput(os.Stderr)
+		set.PrintDefaults()
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	log = logger.NewZerologLogger(cfg.Log.Level, logger.NewWriter(cfg.Log.Format, os.Stderr))
+
+	db, err := database.NewDatabase(&database.Opts{
+		Config: cfg.Database,
+		Logger: log,
+	})
+	if err != nil {
+		return err
+	}
+
+	application := app.New(&app.Opts{Shutdown: cfg.Shutdown, Logger: log})
+	application.Register("database", app.Func{StopFunc: func(context.Context) error {
+		return db.Close()
+	}})
+	if err := application.Start(context.Background()); err != nil {
+		return err
+	}
+	defer func() {
+		if err := application.Stop(); err != nil {
+			log.Error(err.Error())
+		}
+	}()
+
+	opts := &seeder.Opts{
+		DB:     db.DB(),
+		Log:    log,
+		Force:  force,
+		Only:   splitList(only),
+		Except: splitList(except),
+	}
+	if !status {
+		opts.Env, opts.IgnoreEnv = cfg.App.Env, ignoreEnv
+		opts.Params = seeder.Params{Count: count, Seed: seed}
+		return seeder.RunAll(opts)
+	}
+
+	list, err := seeder.Statuses(opts)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEEDER\tSTATUS\tRAN AT")
+	for _, s := range list {
+		ranAt := ""
+		if !s.RanAt.IsZero() {
+			ranAt = s.RanAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.State, ranAt)
+	}
+	return w.Flush()
+}
+
+// splitArgs moves the flags defined in set, and their values, out of args.
+func splitArgs(set *flag.FlagSet, args []string) (own, rest []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		fl := set.Lookup(name)
+		if !strings.HasPrefix(arg, "-") || fl == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		own = append(own, arg)
+		if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			own = append(own, args[i])
+		}
+	}
+	return own, rest
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -3,27 +3,74 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"net"
 	"os"
 	"os/signal"
+	"syscall"
 
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/admin"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/app"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tlsconfig"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tracing"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/interceptor"
+	httpserver "github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/http/server"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/socket"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/upgrade"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	log := logger.NewZerologLogger("info", os.Stderr)
 
-	cfg, err := config.NewConfig(log)
+	cfg, err := config.NewConfig(log, os.Args[1:]...)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0) // the flags were printed
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	// The logger above only reports config errors, the config picks the level and format.
+	log = logger.NewZerologLogger(cfg.Log.Level, logger.NewWriter(cfg.Log.Format, os.Stderr))
+	log.Info("Config loaded", logger.Field{Key: "app_env", Value: cfg.App.Env})
+
+	tlsConfig, err := tlsconfig.New(cfg.TLS)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
+	// Components are started in dependency order and stopped in reverse.
+	application := app.New(&app.Opts{
+		Shutdown: cfg.Shutdown,
+		Logger:   log,
+	})
+
+	// The settings that can change at runtime are applied by the subscribers below.
+	watcher := config.NewWatcher(&config.WatcherOpts{
+		Config: cfg,
+		Logger: log,
+	})
+	config.OnChange(watcher, func(_, next *config.Log) {
+		_ = logger.SetLevel(next.Level) // validated by the config
+	})
+
+	tracer, err := tracing.NewProvider(ctx, &tracing.Opts{
+		Config: cfg.Tracing,
+		Logger: log,
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	application.Register("tracer provider", app.Func{StopFunc: tracer.Shutdown})
+
 	db, err := database.NewDatabase(&database.Opts{
 		Config: cfg.Database,
 		Logger: log,
@@ -31,27 +78,175 @@ func main() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	application.Register("database", app.Func{StopFunc: func(context.Context) error {
+		return db.Close()
+	}})
+	config.OnChange(watcher, func(_, next *config.Database) {
+		if err := database.ConfigurePool(db.DB(), next); err != nil {
+			log.Error("Failed to apply the database pool settings", logger.Field{Key: "error", Value: err.Error()})
+		}
+	})
+
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m, err = metrics.NewMetrics(&metrics.Opts{Database: db})
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	upgrader, err := upgrade.New(&upgrade.Opts{
+		Config: cfg.Upgrade,
+		Logger: log,
+		Dotenv: cfg.Dotenv(),
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	application.Register("upgrader", app.Func{StopFunc: func(context.Context) error {
+		upgrader.Stop()
+		return nil
+	}})
+
+	// listen creates the listeners up front, so they are all inherited from the
+	// previous process on an upgrade before it's told this one is ready.
+	listen := func(url string) net.Listener {
+		listener, err := socket.Listen(url, &socket.Opts{
+			SocketMode: cfg.GRPCServer.SocketMode,
+			Inheritor:  upgrader.Inheritor(),
+		})
+		if err != nil {
+			log.Fatal("failed to create listener",
+				logger.Field{Key: "address", Value: url},
+				logger.Field{Key: "error", Value: err.Error()},
+			)
+		}
+		return listener
+	}
 
 	grpcServer := server.NewServer(&server.Opts{
-		Config:   cfg.GRPCServer,
-		Logger:   log,
-		Database: db,
+		Config:             cfg.GRPCServer,
+		ConcurrencyLimiter: cfg.ConcurrencyLimiter,
+		Logger:             log,
+		Database:           db,
+		Metrics:            m,
+		Compression:        cfg.Compression,
+		TLS:                tlsConfig,
 	})
-	go func() {
-		err = grpcServer.Serve()
-		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
-			stop()
+
+	if grpcServer.Limiter != nil {
+		if m != nil {
+			if err := m.RegisterConcurrencyLimiter(grpcServer.Limiter); err != nil {
+				log.Fatal(err.Error())
+			}
 		}
-	}()
+		config.OnChange(watcher, func(_, next *config.ConcurrencyLimiter) {
+			grpcServer.Limiter.Update(&interceptor.AdaptiveLimiterOpts{
+				MinLimit:  next.MinLimit,
+				MaxLimit:  next.MaxLimit,
+				Smoothing: next.Smoothing,
+				Tolerance: next.Tolerance,
+			})
+		})
+	}
 
-	<-ctx.Done()
+	// With a single port the HTTP transport and metrics are served on the gRPC listener.
+	multiplex := cfg.GRPCServer.MultiplexHTTP && cfg.HTTPServer.Enabled
 
-	log.Warn("Shutdown signal received, closing services!")
+	var httpServer *httpserver.HTTPServer
+	if cfg.HTTPServer.Enabled {
+		opts := &httpserver.Opts{
+			Config:      cfg.HTTPServer,
+			Logger:      log,
+			Conn:        grpcServer.InProcess,
+			Compression: cfg.Compression,
+			TLS:         tlsConfig,
+		}
+		if multiplex && m != nil {
+			opts.Metrics = m.Handler()
+		}
+
+		httpServer, err = httpserver.NewServer(opts)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		if multiplex {
+			grpcServer.ShareListener(httpServer.Server)
+		}
+	}
 
-	grpcServer.Server.GracefulStop()
+	// The gRPC server also drains the HTTP transport when it shares its listener.
+	grpcListener := listen(cfg.GRPCServer.URL)
+	application.Register("grpc server", app.NewService(func() error {
+		if err := grpcServer.ServeListener(grpcListener); !errors.Is(err, grpc.ErrServerStopped) {
+			return err
+		}
+		return nil
+	}, grpcServer.Shutdown), "database", "tracer provider", "upgrader")
+	application.OnPreStop("grpc health", grpcServer.Health.Shutdown)
+	servers := []string{"grpc server"}
+
+	// The HTTP transport calls the gRPC services in-process.
+	if httpServer != nil && !multiplex {
+		listener := listen(cfg.HTTPServer.URL)
+		application.Register("http server", app.NewService(func() error {
+			return httpServer.ServeListener(listener)
+		}, httpServer.Shutdown), "grpc server")
+		servers = append(servers, "http server")
+	}
+
+	if m != nil && !multiplex {
+		metricsServer := metrics.NewServer(&metrics.ServerOpts{
+			Config:  cfg.Metrics,
+			Logger:  log,
+			Metrics: m,
+		})
+		listener := listen(cfg.Metrics.URL)
+		application.Register("metrics server", app.NewService(func() error {
+			return metricsServer.ServeListener(listener)
+		}, metricsServer.Shutdown), "database", "upgrader")
+		servers = append(servers, "metrics server")
+	}
+
+	if cfg.Admin.Enabled {
+		adminServer := admin.NewServer(&admin.Opts{
+			Config:    cfg.Admin,
+			AppConfig: watcher.Current,
+			Logger:    log,
+		})
+		listener := listen(cfg.Admin.URL)
+		application.Register("admin server", app.NewService(func() error {
+			return adminServer.ServeListener(listener)
+		}, adminServer.Shutdown), "upgrader")
+		servers = append(servers, "admin server")
+	}
+
+	// Once every server is up the previous process, if any, is told to exit.
+	application.Register("upgrade handoff", app.Func{StartFunc: func(ctx context.Context) error {
+		if err := upgrader.Ready(); err != nil {
+			return err
+		}
+		go upgrader.HandleSignals(ctx)
+		return nil
+	}}, servers...)
+
+	// Reloads only start once the application is serving.
+	application.Register("config watcher", watcher, servers...)
+
+	go func() {
+		select {
+		case <-upgrader.Exit():
+			// The new process already answers on the same sockets, so there's no pre-stop phase.
+			log.Info("New process is serving, draining this one")
+			application.Exit()
+		case <-ctx.Done():
+		}
+	}()
 
-	if err := db.Close(); err != nil {
-		log.Error("failed to close database client", logger.Field{Key: "error", Value: err.Error()})
+	if err := application.Run(ctx); err != nil {
+		log.Error("Shutdown completed with errors", logger.Field{Key: "error", Value: err.Error()})
+		return
 	}
 
 	log.Info("Shutdown complete!")
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/buildinfo"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+type Opts struct {
+	Config    *config.Admin
+	AppConfig func() *config.Config // returns the config in effect, e.g Watcher.Current, served redacted on /debug/config
+	Logger    logger.Logger
+}
+
+type Server struct {
+	Server *http.Server
+	Config *config.Admin
+	Logger logger.Logger
+}
+
+func NewServer(opts *Opts) *Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+
+	mux.HandleFunc("/debug/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, struct {
+			buildinfo.Info
+			Goroutines int `json:"goroutines"`
+			NumCPU     int `json:"num_cpu"`
+		}{
+			Info:       buildinfo.Get(),
+			Goroutines: runtime.NumGoroutine(),
+			NumCPU:     runtime.NumCPU(),
+		})
+	})
+
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, opts.AppConfig().Redacted())
+	})
+
+	return &Server{
+		Server: &http.Server{
+			Handler:           tokenAuth(opts.Config.Token, mux),
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		Config: opts.Config,
+		Logger: opts.Logger,
+	}
+}
+
+func (s *Server) ServeListener(listener net.Listener) error {
+	s.Logger.Info("Admin server started", logger.Field{Key: "address", Value: listener.Addr().String()})
+	if err := s.Server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.Logger.Error("Admin server failed", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Serve() error {
+	url := s.Config.URL
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		s.Logger.Error("Failed to create admin listener",
+			logger.Field{Key: "address", Value: url},
+			logger.Field{Key: "error", Value: err.Error()},
+		)
+		return err
+	}
+	return s.ServeListener(listener)
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
+
+// tokenAuth requires "Authorization: Bearer <token>" on every request when a token
+// is configured. Without a token the listener relies on being bound to localhost.
+func tokenAuth(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
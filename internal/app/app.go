@@ -0,0 +1,158 @@
+// Package app starts the components of the application in dependency order and
+// stops them in reverse, through the graceful shutdown sequence.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/dag"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/shutdown"
+)
+
+// Component is a part of the application, e.g a server or a database client.
+// Start must return once the component is started, long running work is done in
+// the background and its failures reported through Failer.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Failer is implemented by components doing background work, an error received
+// from Err stops the application.
+type Failer interface {
+	Err() <-chan error
+}
+
+type Opts struct {
+	Shutdown *config.Shutdown
+	Logger   logger.Logger
+}
+
+type App struct {
+	Logger     logger.Logger
+	graph      *dag.Graph
+	err        error // first registration error, returned by Start
+	components map[string]Component
+	sequence   *shutdown.Sequence
+	failed     chan error
+	exit       chan struct{}
+	exitOnce   sync.Once
+}
+
+func New(opts *Opts) *App {
+	return &App{
+		Logger:     opts.Logger,
+		graph:      dag.New(),
+		components: map[string]Component{},
+		sequence: shutdown.NewSequence(&shutdown.Opts{
+			Config: opts.Shutdown,
+			Logger: opts.Logger,
+		}),
+		failed: make(chan error, 1),
+		exit:   make(chan struct{}),
+	}
+}
+
+// Register adds a component started after the components it depends on and
+// stopped before them. Invalid registrations, e.g a duplicate name, fail Start.
+func (a *App) Register(name string, c Component, dependsOn ...string) {
+	if err := a.graph.Add(name, dependsOn...); err != nil {
+		a.err = errors.Join(a.err, err)
+		return
+	}
+	a.components[name] = c
+}
+
+// OnPreStop registers a function run when a shutdown signal is received, before
+// anything is stopped, e.g to report the instance as not serving.
+func (a *App) OnPreStop(name string, fn func()) {
+	a.sequence.OnPreStop(name, fn)
+}
+
+// Start starts every component in dependency order. When one fails the ones
+// already started are stopped again.
+func (a *App) Start(ctx context.Context) error {
+	order, err := a.graph.Sort()
+	if err = errors.Join(a.err, err); err != nil {
+		return fmt.Errorf("start components: %w", err)
+	}
+
+	for _, name := range order {
+		c := a.components[name]
+		if err := c.Start(ctx); err != nil {
+			a.Logger.Error("Failed to start component",
+				logger.Field{Key: "name", Value: name},
+				logger.Field{Key: "error", Value: err.Error()},
+			)
+			return errors.Join(fmt.Errorf("start %s: %w", name, err), a.Stop())
+		}
+		a.sequence.Add(name, c.Stop)
+		a.watch(name, c)
+		a.Logger.Info("Component started", logger.Field{Key: "name", Value: name})
+	}
+
+	return nil
+}
+
+// watch forwards the first background failure of c.
+func (a *App) watch(name string, c Component) {
+	f, ok := c.(Failer)
+	if !ok {
+		return
+	}
+	go func() {
+		err, ok := <-f.Err()
+		if !ok || err == nil {
+			return
+		}
+		select {
+		case a.failed <- fmt.Errorf("%s: %w", name, err):
+		default: // the application is already stopping
+		}
+	}()
+}
+
+// Wait blocks until ctx is done, Exit is called or a component failed, the
+// failure is returned.
+func (a *App) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		a.Logger.Warn("Shutdown signal received, closing services!")
+		// Keep serving while load balancers take the instance out of rotation.
+		a.sequence.PreStop()
+		return nil
+	case <-a.exit:
+		return nil
+	case err := <-a.failed:
+		a.Logger.Error("Component failed, closing services!", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+}
+
+// Exit makes Wait return without the pre-stop phase, e.g when another process
+// already took over the listeners.
+func (a *App) Exit() {
+	a.exitOnce.Do(func() {
+		close(a.exit)
+	})
+}
+
+// Stop stops the started components in reverse order, each within the drain timeout.
+func (a *App) Stop() error {
+	return a.sequence.Drain()
+}
+
+// Run starts the components, waits for a shutdown signal (ctx being done) or a
+// failure and stops them again.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+	err := a.Wait(ctx)
+	return errors.Join(err, a.Stop())
+}
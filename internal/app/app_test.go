@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/app"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+// recorder records the order components are started and stopped in.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (r *recorder) component(name string, startErr error) app.Func {
+	return app.Func{
+		StartFunc: func(context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		StopFunc: func(context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func newApp(preStopDelay time.Duration) *app.App {
+	return app.New(&app.Opts{
+		Shutdown: &config.Shutdown{PreStopDelay: preStopDelay, DrainTimeout: time.Second},
+		Logger:   logger.NewZerologLogger("info", io.Discard),
+	})
+}
+
+// TestApp_Run verifies components are started in dependency order, the pre-stop
+// hooks run once ctx is done and components are stopped in reverse order.
+func TestApp_Run(t *testing.T) {
+	var r recorder
+	a := newApp(0)
+	a.Register("http", r.component("http", nil), "grpc")
+	a.Register("grpc", r.component("grpc", nil), "database")
+	a.Register("database", r.component("database", nil))
+	a.OnPreStop("health", func() { r.add("not serving") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return len(r.get()) == 3 }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []string{
+		"start database", "start grpc", "start http",
+		"not serving",
+		"stop http", "stop grpc", "stop database",
+	}, r.get())
+}
+
+// TestApp_StartFailure verifies a component failing to start stops the ones
+// already started and the rest are never started.
+func TestApp_StartFailure(t *testing.T) {
+	var r recorder
+	a := newApp(0)
+	a.Register("database", r.component("database", nil))
+	a.Register("grpc", r.component("grpc", errors.New("address in use")), "database")
+	a.Register("http", r.component("http", nil), "grpc")
+
+	err := a.Run(context.Background())
+	require.ErrorContains(t, err, "start grpc: address in use")
+	assert.Equal(t, []string{"start database", "start grpc", "stop database"}, r.get())
+}
+
+// TestApp_ComponentFailure verifies an error reported in the background stops
+// every component, without the pre-stop delay, and is returned by Run.
+func TestApp_ComponentFailure(t *testing.T) {
+	var r recorder
+	a := newApp(time.Hour)
+	a.Register("database", r.component("database", nil))
+
+	serveErr := make(chan error)
+	a.Register("grpc", app.NewService(func() error {
+		return <-serveErr
+	}, func(context.Context) error {
+		r.add("stop grpc")
+		return nil
+	}), "database")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(context.Background())
+	}()
+
+	serveErr <- errors.New("listener closed")
+	err := <-done
+	require.ErrorContains(t, err, "grpc: listener closed")
+	assert.Equal(t, []string{"start database", "stop grpc", "stop database"}, r.get())
+}
+
+// TestApp_Exit verifies Exit stops the components without the pre-stop phase.
+func TestApp_Exit(t *testing.T) {
+	var r recorder
+	a := newApp(time.Hour)
+	a.Register("database", r.component("database", nil))
+	a.OnPreStop("health", func() { r.add("not serving") })
+
+	require.NoError(t, a.Start(context.Background()))
+	a.Exit()
+	a.Exit() // safe to call more than once
+
+	require.NoError(t, a.Wait(context.Background()))
+	require.NoError(t, a.Stop())
+	assert.Equal(t, []string{"start database", "stop database"}, r.get())
+}
+
+// TestApp_InvalidRegistration verifies duplicate names and unknown dependencies
+// fail Start before anything is started.
+func TestApp_InvalidRegistration(t *testing.T) {
+	var r recorder
+	a := newApp(0)
+	a.Register("database", r.component("database", nil))
+	a.Register("database", r.component("database", nil))
+	a.Register("grpc", r.component("grpc", nil), "cache")
+
+	err := a.Start(context.Background())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `"database" is already added`)
+	assert.ErrorContains(t, err, `"grpc" depends on unknown "cache"`)
+	assert.Empty(t, r.get())
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+)
+
+// Func adapts a pair of functions to a Component, either may be nil.
+type Func struct {
+	StartFunc func(ctx context.Context) error
+	StopFunc  func(ctx context.Context) error
+}
+
+func (f Func) Start(ctx context.Context) error {
+	if f.StartFunc == nil {
+		return nil
+	}
+	return f.StartFunc(ctx)
+}
+
+func (f Func) Stop(ctx context.Context) error {
+	if f.StopFunc == nil {
+		return nil
+	}
+	return f.StopFunc(ctx)
+}
+
+// Service adapts a blocking serve function, e.g a server's ServeListener, to a
+// Component. An error returned by serve stops the application.
+type Service struct {
+	serve    func() error
+	shutdown func(ctx context.Context) error
+	err      chan error
+}
+
+func NewService(serve func() error, shutdown func(ctx context.Context) error) *Service {
+	return &Service{
+		serve:    serve,
+		shutdown: shutdown,
+		err:      make(chan error, 1),
+	}
+}
+
+func (s *Service) Start(context.Context) error {
+	go func() {
+		if err := s.serve(); err != nil {
+			s.err <- err
+		}
+	}()
+	return nil
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	return s.shutdown(ctx)
+}
+
+func (s *Service) Err() <-chan error {
+	return s.err
+}
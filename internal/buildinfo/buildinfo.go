@@ -0,0 +1,42 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is set at build time, e.g:
+// go build -ldflags "-X github.com/sagarmaheshwary/go-microservice-boilerplate/internal/buildinfo.Version=v1.2.3"
+var Version = "dev"
+
+type Info struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary. Revision and build time
+// come from the VCS data embedded by the go toolchain and are empty when unavailable.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,22 @@
+package buildinfo_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/buildinfo"
+)
+
+// TestGet verifies the version variable and go version are reported.
+func TestGet(t *testing.T) {
+	original := buildinfo.Version
+	buildinfo.Version = "v1.2.3"
+	defer func() { buildinfo.Version = original }()
+
+	info := buildinfo.Get()
+
+	assert.Equal(t, "v1.2.3", info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
@@ -0,0 +1,54 @@
+// Package compression registers the gzip and zstd compressors with gRPC and
+// compresses the responses of the HTTP transport. The encoding is negotiated per
+// request from what the client accepts, in the order of preference configured.
+package compression
+
+import (
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+)
+
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+func init() {
+	encoding.RegisterCompressor(newZstdCompressor())
+}
+
+// Negotiate returns the first of the preferred encodings the client accepts, or
+// an empty string when there's none.
+func Negotiate(accepted, preferred []string) string {
+	for _, p := range preferred {
+		for _, a := range accepted {
+			if a == p || a == "*" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// ParseAcceptEncoding returns the encodings listed in an Accept-Encoding header,
+// the ones explicitly refused with q=0 are left out.
+func ParseAcceptEncoding(header string) []string {
+	var accepted []string
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		}
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted = append(accepted, name)
+	}
+	return accepted
+}
@@ -0,0 +1,57 @@
+package compression_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/compression"
+)
+
+// TestNegotiate verifies the server preference wins among the accepted encodings.
+func TestNegotiate(t *testing.T) {
+	preferred := []string{"zstd", "gzip"}
+
+	assert.Equal(t, "zstd", compression.Negotiate([]string{"gzip", "zstd"}, preferred))
+	assert.Equal(t, "gzip", compression.Negotiate([]string{"gzip", "br"}, preferred))
+	assert.Equal(t, "zstd", compression.Negotiate([]string{"*"}, preferred))
+	assert.Empty(t, compression.Negotiate([]string{"br"}, preferred))
+	assert.Empty(t, compression.Negotiate(nil, preferred))
+}
+
+// TestParseAcceptEncoding verifies q-values are parsed and refused encodings dropped.
+func TestParseAcceptEncoding(t *testing.T) {
+	assert.Equal(t, []string{"gzip", "br"}, compression.ParseAcceptEncoding("gzip, deflate;q=0, BR;q=0.5"))
+	assert.Equal(t, []string{"zstd"}, compression.ParseAcceptEncoding(" zstd ;q=1.0,,"))
+	assert.Empty(t, compression.ParseAcceptEncoding(""))
+}
+
+// TestZstdCompressor verifies the zstd compressor is registered with gRPC and
+// messages survive a round trip, also with pooled encoders and decoders.
+func TestZstdCompressor(t *testing.T) {
+	c := encoding.GetCompressor(compression.Zstd)
+	require.NotNil(t, c)
+
+	for i := 0; i < 3; i++ {
+		msg := strings.Repeat("hello world ", 100*(i+1))
+
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(msg))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Less(t, buf.Len(), len(msg))
+
+		r, err := c.Decompress(&buf)
+		require.NoError(t, err)
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, msg, string(out))
+	}
+}
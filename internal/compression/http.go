@@ -0,0 +1,182 @@
+package compression
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+)
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	zstdWriters = sync.Pool{New: func() any {
+		enc, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return enc
+	}}
+)
+
+// Handler compresses the responses of next with the preferred encoding the client
+// accepts. The response is buffered until it reaches cfg.MinSize, smaller ones
+// are sent as they are.
+func Handler(cfg *config.Compression, next http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := Negotiate(ParseAcceptEncoding(r.Header.Get("Accept-Encoding")), cfg.Algorithms)
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &responseWriter{ResponseWriter: w, encoding: encoding, minSize: cfg.MinSize, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// responseWriter decides whether to compress once MinSize bytes were written or
+// the handler is done.
+type responseWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status      int
+	wroteHeader bool // by the handler, it's only forwarded once decided
+	decided     bool
+	buf         []byte
+	enc         io.WriteCloser
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if w.decided {
+		return w.write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *responseWriter) write(p []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sends the header and the buffered bytes, compressed when the response
+// is large enough and not encoded by the handler already.
+func (w *responseWriter) decide(large bool) error {
+	w.decided = true
+	h := w.Header()
+
+	compress := large && h.Get("Content-Encoding") == "" &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified && w.status >= http.StatusOK
+	if compress {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = newEncoder(w.encoding, w.ResponseWriter)
+	} else if h.Get("Content-Length") == "" && len(w.buf) > 0 {
+		h.Set("Content-Length", strconv.Itoa(len(w.buf)))
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+// Close flushes what's left once the handler returned.
+func (w *responseWriter) Close() error {
+	if !w.decided {
+		if !w.wroteHeader {
+			return nil // nothing was written, net/http sends the default response
+		}
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	w.enc = nil
+	return err
+}
+
+// Flush sends the response so far, e.g for streaming handlers, compressing it
+// only if it already reached the minimum size.
+func (w *responseWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.minSize)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// pooledWriter returns the encoder to its pool once closed.
+type pooledWriter struct {
+	io.WriteCloser
+	flush func() error
+	put   func()
+}
+
+func (w *pooledWriter) Flush() error {
+	return w.flush()
+}
+
+func (w *pooledWriter) Close() error {
+	err := w.WriteCloser.Close()
+	w.put()
+	return err
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == Zstd {
+		enc := zstdWriters.Get().(*zstd.Encoder)
+		enc.Reset(w)
+		return &pooledWriter{WriteCloser: enc, flush: enc.Flush, put: func() { zstdWriters.Put(enc) }}
+	}
+
+	gz := gzipWriters.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return &pooledWriter{WriteCloser: gz, flush: gz.Flush, put: func() { gzipWriters.Put(gz) }}
+}
@@ -0,0 +1,115 @@
+package compression_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/compression"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+)
+
+var testConfig = &config.Compression{Enabled: true, MinSize: 100, Algorithms: []string{"zstd", "gzip"}}
+
+// serve runs body through the compression handler with the given Accept-Encoding.
+func serve(cfg *config.Compression, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	compression.Handler(cfg, handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+
+	var r io.Reader
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		r = gz
+	case "zstd":
+		dec, err := zstd.NewReader(body)
+		require.NoError(t, err)
+		defer dec.Close()
+		r = dec
+	default:
+		r = body
+	}
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+// TestHandler verifies responses are compressed with the preferred encoding the
+// client accepts, once they reach the minimum size.
+func TestHandler(t *testing.T) {
+	large := strings.Repeat(`{"message":"Hello, Alice!"}`, 20)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		body           string
+		encoding       string
+	}{
+		{name: "preferred encoding", acceptEncoding: "gzip, zstd", body: large, encoding: "zstd"},
+		{name: "only gzip accepted", acceptEncoding: "gzip", body: large, encoding: "gzip"},
+		{name: "nothing accepted", body: large},
+		{name: "below minimum size", acceptEncoding: "gzip", body: "small"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(testConfig, tt.acceptEncoding, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				// Written in chunks, the decision is taken once the minimum size is reached.
+				for i := 0; i < len(tt.body); i += 30 {
+					_, _ = io.WriteString(w, tt.body[i:min(i+30, len(tt.body))])
+				}
+			})
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, tt.encoding, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, decode(t, tt.encoding, rec.Body))
+		})
+	}
+}
+
+// TestHandler_Passthrough verifies responses encoded by the handler, empty ones
+// and a disabled config are left alone.
+func TestHandler_Passthrough(t *testing.T) {
+	large := strings.Repeat("a", 200)
+
+	rec := serve(testConfig, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = io.WriteString(w, large)
+	})
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, rec.Body.String())
+
+	rec = serve(testConfig, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	rec = serve(&config.Compression{Algorithms: []string{"gzip"}}, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, large)
+	})
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, rec.Body.String())
+}
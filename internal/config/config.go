@@ -1,55 +1,217 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"os"
-	"path"
-	"path/filepath"
-	"runtime"
-	"strconv"
+	"sort"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofor-little/env"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/netaddr"
 )
 
 type LoaderOptions struct {
+	// EnvPath is the .env file to load when neither --env-file nor ENV_FILE is
+	// set, by default .env files are discovered (see envFiles).
 	EnvPath   string
 	EnvLoader func(string) error
 	Logger    logger.Logger
+	// Args are the command line flags: --config selects the config file and every
+	// setting has a flag named after its environment variable, e.g --grpc-server-url.
+	Args []string
+	// SecretProvider resolves the secret://name references, by default the
+	// encrypted file configured in Secrets is used.
+	SecretProvider SecretProvider
 }
 
+// SecretProvider resolves the value of a secret from its name.
+type SecretProvider interface {
+	Resolve(ctx context.Context, name string) (string, error)
+}
+
+// Config holds every setting. The sections and fields tagged `reload:"restart"`
+// only take effect on a restart, the others can be applied by a Watcher.
 type Config struct {
-	GRPCServer *GRPCServer `validate:"required"`
-	Database   *Database   `validate:"required"`
+	App                *App                `config:"app" validate:"required" reload:"restart"`
+	GRPCServer         *GRPCServer         `config:"grpc_server" validate:"required" reload:"restart"`
+	HTTPServer         *HTTPServer         `config:"http_server" validate:"required" reload:"restart"`
+	TLS                *TLS                `config:"tls" validate:"required" reload:"restart"`
+	ConcurrencyLimiter *ConcurrencyLimiter `config:"concurrency_limiter" validate:"required"`
+	Database           *Database           `config:"database" validate:"required"`
+	Log                *Log                `config:"log" validate:"required"`
+	Metrics            *Metrics            `config:"metrics" validate:"required" reload:"restart"`
+	Tracing            *Tracing            `config:"tracing" validate:"required" reload:"restart"`
+	Admin              *Admin              `config:"admin" validate:"required" reload:"restart"`
+	Upgrade            *Upgrade            `config:"upgrade" validate:"required" reload:"restart"`
+	Shutdown           *Shutdown           `config:"shutdown" validate:"required" reload:"restart"`
+	Compression        *Compression        `config:"compression" validate:"required" reload:"restart"`
+	Secrets            *Secrets            `config:"secrets" validate:"required" reload:"restart"`
+
+	// sensitive holds the keys of the settings read from a file or a secret
+	// provider, they are redacted like the ones tagged secret.
+	sensitive map[string]bool
+	// sources holds what set each setting that isn't a default, see Source.
+	sources map[string]string
+	// dotenv holds the variables set by the .env files, see Dotenv.
+	dotenv []string
+	// loader and file are kept to reload the config, see Watcher.
+	loader LoaderOptions
+	file   string
+}
+
+// App selects the profile changing the defaults, see profiles. Production also
+// refuses settings that aren't safe there, e.g serving without TLS.
+type App struct {
+	Env      string `config:"env" env:"APP_ENV" validate:"omitempty,oneof=development test staging production"`
+	CheckEnv bool   `config:"check_env" env:"APP_CHECK_ENV"` // warns about the variables sharing a prefix with the settings but matching none, e.g misspelled ones
 }
 
 type GRPCServer struct {
-	URL string `validate:"required,hostname_port"`
+	// URL is a host:port, unix:///path/to.sock, unix-abstract://name or systemd://[name]
+	// for a socket inherited through systemd socket activation.
+	URL               string      `config:"url" env:"GRPC_SERVER_URL" validate:"required,listen_addr"`
+	SocketMode        os.FileMode `config:"socket_mode" env:"GRPC_SOCKET_MODE" validate:"lte=0777"` // permissions of the unix socket file
+	ReflectionEnabled bool        `config:"reflection_enabled" env:"GRPC_REFLECTION_ENABLED"`       // exposes the service schemas for grpcurl/grpcui, keep off in production
+	ChannelzEnabled   bool        `config:"channelz_enabled" env:"GRPC_CHANNELZ_ENABLED"`
+	// WebEnabled serves gRPC-Web and the Connect protocol next to gRPC on URL.
+	WebEnabled         bool     `config:"web_enabled" env:"GRPC_WEB_ENABLED"`
+	CORSAllowedOrigins []string `config:"cors_allowed_origins" env:"GRPC_CORS_ALLOWED_ORIGINS" validate:"dive,required"`
+	// MultiplexHTTP serves the HTTP transport (gateway, health, metrics) on URL too,
+	// for environments exposing a single port. HTTPServer.URL and Metrics.URL are unused.
+	MultiplexHTTP bool `config:"multiplex_http" env:"GRPC_MULTIPLEX_HTTP"`
+
+	// Connection settings, see google.golang.org/grpc/keepalive for the keepalive ones.
+	KeepaliveTime                time.Duration `config:"keepalive_time" env:"GRPC_KEEPALIVE_TIME" validate:"gte=1s"`                               // pings clients after this long without activity
+	KeepaliveTimeout             time.Duration `config:"keepalive_timeout" env:"GRPC_KEEPALIVE_TIMEOUT" validate:"gt=0"`                           // closes the connection when a ping isn't acknowledged in time
+	KeepaliveMinTime             time.Duration `config:"keepalive_min_time" env:"GRPC_KEEPALIVE_MIN_TIME" validate:"gte=0"`                        // clients pinging more often are disconnected
+	KeepalivePermitWithoutStream bool          `config:"keepalive_permit_without_stream" env:"GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM"`               // allows client pings without active RPCs
+	MaxConnectionIdle            time.Duration `config:"max_connection_idle" env:"GRPC_MAX_CONNECTION_IDLE" validate:"gte=0"`                      // 0 keeps idle connections open
+	MaxConnectionAge             time.Duration `config:"max_connection_age" env:"GRPC_MAX_CONNECTION_AGE" validate:"gte=0"`                        // spreads long-lived connections over new instances, 0 is unlimited
+	MaxConnectionAgeGrace        time.Duration `config:"max_connection_age_grace" env:"GRPC_MAX_CONNECTION_AGE_GRACE" validate:"gte=0"`            // time left to in-flight RPCs once MaxConnectionAge is reached, 0 is unlimited
+	MaxRecvMsgSize               int           `config:"max_recv_msg_size" env:"GRPC_MAX_RECV_MSG_SIZE" validate:"gt=0"`                           // bytes
+	MaxSendMsgSize               int           `config:"max_send_msg_size" env:"GRPC_MAX_SEND_MSG_SIZE" validate:"gt=0"`                           // bytes
+	MaxConcurrentStreams         int           `config:"max_concurrent_streams" env:"GRPC_MAX_CONCURRENT_STREAMS" validate:"gte=0,lte=4294967295"` // per connection, 0 is unlimited
+	ConnectionTimeout            time.Duration `config:"connection_timeout" env:"GRPC_CONNECTION_TIMEOUT" validate:"gt=0"`                         // for new connections to complete the handshake, or send their request headers with gRPC-Web
+}
+
+type HTTPServer struct {
+	Enabled          bool   `config:"enabled" env:"HTTP_SERVER_ENABLED"`
+	URL              string `config:"url" env:"HTTP_SERVER_URL" validate:"required,hostname_port"`
+	SwaggerUIEnabled bool   `config:"swagger_ui_enabled" env:"HTTP_SWAGGER_UI_ENABLED"`
+}
+
+// TLS secures the gRPC port, with everything it serves, and the HTTP transport.
+type TLS struct {
+	Enabled      bool   `config:"enabled" env:"TLS_ENABLED"`
+	CertFile     string `config:"cert_file" env:"TLS_CERT_FILE" validate:"required_if=Enabled true"`
+	KeyFile      string `config:"key_file" env:"TLS_KEY_FILE" validate:"required_if=Enabled true"`
+	ClientCAFile string `config:"client_ca_file" env:"TLS_CLIENT_CA_FILE"` // clients must present a certificate signed by it (mTLS)
+	MinVersion   string `config:"min_version" env:"TLS_MIN_VERSION" validate:"oneof=1.2 1.3"`
+}
+
+type ConcurrencyLimiter struct {
+	Enabled      bool    `config:"enabled" env:"GRPC_CONCURRENCY_LIMIT_ENABLED" reload:"restart"`
+	InitialLimit int     `config:"initial_limit" env:"GRPC_CONCURRENCY_LIMIT_INITIAL" validate:"gte=1" reload:"restart"`
+	MinLimit     int     `config:"min_limit" env:"GRPC_CONCURRENCY_LIMIT_MIN" validate:"gte=1,ltefield=InitialLimit"`
+	MaxLimit     int     `config:"max_limit" env:"GRPC_CONCURRENCY_LIMIT_MAX" validate:"gtefield=InitialLimit"`
+	Smoothing    float64 `config:"smoothing" env:"GRPC_CONCURRENCY_LIMIT_SMOOTHING" validate:"gt=0,lte=1"`
+	Tolerance    float64 `config:"tolerance" env:"GRPC_CONCURRENCY_LIMIT_TOLERANCE" validate:"gte=1"` // how much latency growth over the baseline is tolerated
 }
 
 type Database struct {
-	DSN                 string        `validate:"required"`
-	Driver              string        `validate:"required,oneof=postgres mysql sqlite"`
-	PoolMaxIdleConns    int           `validate:"gte=0"`
-	PoolMaxOpenConns    int           `validate:"gte=0"`
-	PoolConnMaxLifetime time.Duration `validate:"gte=0"` // must be non-negative
+	DSN                 string        `config:"dsn" env:"DATABASE_DSN" validate:"required" secret:"true" reload:"restart"`
+	Driver              string        `config:"driver" env:"DATABASE_DRIVER" validate:"required,oneof=postgres mysql sqlite" reload:"restart"`
+	PoolMaxIdleConns    int           `config:"pool_max_idle_conns" env:"DATABASE_POOL_MAX_IDLE" validate:"gte=0"`
+	PoolMaxOpenConns    int           `config:"pool_max_open_conns" env:"DATABASE_POOL_MAX_OPEN" validate:"gte=0"`
+	PoolConnMaxLifetime time.Duration `config:"pool_conn_max_lifetime" env:"DATABASE_POOL_MAX_LIFETIME" validate:"gte=0"` // must be non-negative
+}
+
+type Log struct {
+	Level  string `config:"level" env:"LOG_LEVEL" validate:"oneof=trace debug info warn error"`
+	Format string `config:"format" env:"LOG_FORMAT" validate:"oneof=json console" reload:"restart"` // console is colored and human readable
+}
+
+type Metrics struct {
+	Enabled bool   `config:"enabled" env:"METRICS_ENABLED"`
+	URL     string `config:"url" env:"METRICS_URL" validate:"required,hostname_port"`
+}
+
+type Tracing struct {
+	Enabled      bool    `config:"enabled" env:"TRACING_ENABLED"`
+	ServiceName  string  `config:"service_name" env:"TRACING_SERVICE_NAME" validate:"required"`
+	Exporter     string  `config:"exporter" env:"TRACING_EXPORTER" validate:"oneof=otlp stdout file"`
+	OTLPEndpoint string  `config:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" validate:"required_if=Exporter otlp"`
+	OTLPInsecure bool    `config:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	FilePath     string  `config:"file_path" env:"TRACING_FILE_PATH" validate:"required_if=Exporter file"`
+	SampleRatio  float64 `config:"sample_ratio" env:"TRACING_SAMPLE_RATIO" validate:"gte=0,lte=1"`
+}
+
+// Admin configures the debug listener serving pprof, build info and the redacted config.
+// It must either be bound to a loopback address or protected with a token.
+type Admin struct {
+	Enabled bool   `config:"enabled" env:"ADMIN_ENABLED"`
+	URL     string `config:"url" env:"ADMIN_URL" validate:"required,hostname_port"`
+	Token   string `config:"token" env:"ADMIN_TOKEN" secret:"true"`
+}
+
+// Upgrade configures zero-downtime binary upgrades: on SIGUSR2 the new
+// binary is started with the listening sockets and the old process exits once it's ready.
+type Upgrade struct {
+	Enabled bool          `config:"enabled" env:"UPGRADE_ENABLED"`
+	PIDFile string        `config:"pid_file" env:"UPGRADE_PID_FILE"`               // the PID of the ready process is written here, e.g for systemd's PIDFile=
+	Timeout time.Duration `config:"timeout" env:"UPGRADE_TIMEOUT" validate:"gt=0"` // how long the new process has to become ready
 }
 
-func NewConfig(log logger.Logger) (*Config, error) {
+// Shutdown bounds the graceful shutdown on SIGINT or SIGTERM.
+type Shutdown struct {
+	PreStopDelay time.Duration `config:"pre_stop_delay" env:"SHUTDOWN_PRE_STOP_DELAY" validate:"gte=0"` // time for load balancers to notice the failing health checks
+	DrainTimeout time.Duration `config:"drain_timeout" env:"SHUTDOWN_DRAIN_TIMEOUT" validate:"gt=0"`    // per component, in-flight requests are cancelled after it
+}
+
+// Compression configures the response compression of gRPC and the HTTP transport.
+type Compression struct {
+	Enabled    bool     `config:"enabled" env:"COMPRESSION_ENABLED"`
+	MinSize    int      `config:"min_size" env:"COMPRESSION_MIN_SIZE" validate:"gte=0"`                          // responses smaller than this many bytes are sent as they are
+	Algorithms []string `config:"algorithms" env:"COMPRESSION_ALGORITHMS" validate:"min=1,dive,oneof=zstd gzip"` // in order of preference
+}
+
+// Secrets configures the encrypted file resolving secret://name references, see
+// the secrets command of the CLI to create it.
+type Secrets struct {
+	File string `config:"file" env:"SECRETS_FILE"`
+	Key  string `config:"key" env:"SECRETS_KEY" validate:"required_with=File" secret:"true"` // base64 encoded 32 byte key
+}
+
+// NewConfig loads the config, args are the command line flags (see LoaderOptions.Args).
+func NewConfig(log logger.Logger, args ...string) (*Config, error) {
 	return NewConfigWithOptions(LoaderOptions{
-		EnvPath: path.Join(rootDir(), "..", ".env"),
-		Logger:  log,
+		Logger: log,
+		Args:   args,
 	})
 }
 
+// NewConfigWithOptions loads the config from, in increasing precedence, the defaults
+// of the APP_ENV profile, the config file, the .env file, the environment and the
+// command line flags.
 func NewConfigWithOptions(opts LoaderOptions) (*Config, error) {
 	log := opts.Logger
 	if log == nil {
 		log = logger.NewZerologLogger("info", os.Stderr)
 	}
 
+	cfg := defaults()
+
+	flags, err := parseFlags(cfg, opts.Args)
+	if err != nil {
+		return nil, err
+	}
+
 	envLoader := opts.EnvLoader
 	if envLoader == nil {
 		envLoader = func(path string) error {
@@ -62,60 +224,208 @@ func NewConfigWithOptions(opts LoaderOptions) (*Config, error) {
 		}
 	}
 
-	if err := envLoader(opts.EnvPath); err == nil {
-		log.Info("Loaded environment variables from" + opts.EnvPath)
+	// .env files don't override variables already set, so the environment wins and
+	// they're loaded from the highest precedence down.
+	preset := envKeys()
+	files := envFiles(flags.envFiles, opts.EnvPath)
+	for i := len(files) - 1; i >= 0; i-- {
+		if err := envLoader(files[i]); err != nil {
+			return nil, fmt.Errorf("load env file: %w", err)
+		}
+	}
+	if len(files) > 0 {
+		log.Info("Loaded environment variables", logger.Field{Key: "files", Value: files})
 	} else {
-		log.Info("failed to load .env file, using system environment variables")
+		log.Info("No .env file found, using system environment variables")
 	}
 
-	cfg := &Config{
-		GRPCServer: &GRPCServer{
-			URL: getEnv("GRPC_SERVER_URL", ":5000"),
-		},
-		Database: &Database{
-			DSN:                 getEnv("DATABASE_DSN", ""),
-			Driver:              getEnv("DATABASE_DRIVER", "postgres"),
-			PoolMaxIdleConns:    getEnvInt("DATABASE_POOL_MAX_IDLE", 10),
-			PoolMaxOpenConns:    getEnvInt("DATABASE_POOL_MAX_OPEN", 100),
-			PoolConnMaxLifetime: getEnvDuration("DATABASE_POOL_MAX_LIFETIME", time.Hour),
-		},
+	configFile := flags.configFile
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(cfg); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+	profile, err := profileOf(flags, configFile)
+	if err != nil {
+		return nil, err
 	}
+	applyProfile(cfg, profile)
 
+	if configFile != "" {
+		if err := loadFile(cfg, configFile); err != nil {
+			return nil, err
+		}
+		log.Info("Loaded config file", logger.Field{Key: "path", Value: configFile})
+	}
+
+	dotenv := map[string]bool{}
+	for key := range envKeys() {
+		dotenv[key] = !preset[key]
+		if !preset[key] {
+			cfg.dotenv = append(cfg.dotenv, key)
+		}
+	}
+	sort.Strings(cfg.dotenv)
+	invalid, err := loadEnv(cfg, dotenv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := flags.apply(); err != nil {
+		return nil, err
+	}
+
+	if cfg.App.CheckEnv {
+		for _, u := range findUnknownEnv(cfg, envKeys()) {
+			fields := []logger.Field{{Key: "name", Value: u.Name}}
+			if u.Suggestion != "" {
+				fields = append(fields, logger.Field{Key: "did_you_mean", Value: u.Suggestion})
+			}
+			log.Warn("Unknown environment variable", fields...)
+		}
+	}
+
+	if err := resolveSecrets(cfg, opts.SecretProvider); err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(cfg, invalid...); err != nil {
+		return nil, err
+	}
+
+	cfg.loader = opts
+	cfg.file = configFile
 	return cfg, nil
 }
 
-func rootDir() string {
-	_, b, _, _ := runtime.Caller(0)
-	d := path.Join(path.Dir(b))
-
-	return filepath.Dir(d)
+// Dotenv returns the variables set by the .env files, the environment didn't set
+// them before the config was loaded.
+func (c *Config) Dotenv() []string {
+	return c.dotenv
 }
 
-func getEnv(key string, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+// validateConfig validates cfg, invalid holds the values that couldn't be parsed
+// and are reported with the validation errors.
+func validateConfig(cfg *Config, invalid ...error) error {
+	validate := validator.New()
+	validate.RegisterStructValidation(adminStructLevelValidation, Admin{})
+	if err := validate.RegisterValidation("listen_addr", validateListenAddr); err != nil {
+		return err
 	}
 
-	return defaultVal
+	errs := invalid
+	if err := validate.Struct(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return &ValidationError{err: errors.Join(errs...), cfg: cfg}
+	}
+	return validateProfile(cfg)
 }
 
-func getEnvInt(key string, defaultVal int) int {
-	if val, err := strconv.Atoi(os.Getenv(key)); err == nil {
-		return val
-	}
+// ValidationError is returned by NewConfig when settings are invalid. It keeps the
+// config that failed, so Explain knows which values are sensitive and what set them.
+type ValidationError struct {
+	err error
+	cfg *Config
+}
 
-	return defaultVal
+func (e *ValidationError) Error() string {
+	return "invalid config: " + e.err.Error()
 }
 
-func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
-	if val, err := time.ParseDuration(os.Getenv(key)); err == nil {
-		return val
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// defaults returns the config used for the settings that aren't set anywhere,
+// before the profile is applied.
+func defaults() *Config {
+	return &Config{
+		App: &App{},
+		GRPCServer: &GRPCServer{
+			URL:        ":5000",
+			SocketMode: 0660,
+
+			KeepaliveTime:     2 * time.Hour,
+			KeepaliveTimeout:  20 * time.Second,
+			KeepaliveMinTime:  5 * time.Minute,
+			MaxRecvMsgSize:    4 << 20,
+			MaxSendMsgSize:    math.MaxInt32,
+			ConnectionTimeout: 2 * time.Minute,
+		},
+		HTTPServer: &HTTPServer{
+			Enabled: true,
+			URL:     ":8080",
+		},
+		TLS: &TLS{
+			MinVersion: "1.2",
+		},
+		ConcurrencyLimiter: &ConcurrencyLimiter{
+			Enabled:      true,
+			InitialLimit: 50,
+			MinLimit:     10,
+			MaxLimit:     1000,
+			Smoothing:    0.2,
+			Tolerance:    1.5,
+		},
+		Database: &Database{
+			Driver:              "postgres",
+			PoolMaxIdleConns:    10,
+			PoolMaxOpenConns:    100,
+			PoolConnMaxLifetime: time.Hour,
+		},
+		Log: &Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Metrics: &Metrics{
+			Enabled: true,
+			URL:     ":9090",
+		},
+		Tracing: &Tracing{
+			ServiceName:  "go-microservice-boilerplate",
+			Exporter:     "otlp",
+			OTLPEndpoint: "localhost:4317",
+			OTLPInsecure: true,
+			SampleRatio:  1,
+		},
+		Admin: &Admin{
+			URL: "127.0.0.1:6060",
+		},
+		Upgrade: &Upgrade{
+			Timeout: time.Minute,
+		},
+		Shutdown: &Shutdown{
+			DrainTimeout: 30 * time.Second,
+		},
+		Compression: &Compression{
+			Enabled:    true,
+			MinSize:    1024,
+			Algorithms: []string{"zstd", "gzip"},
+		},
+		Secrets:   &Secrets{},
+		sensitive: map[string]bool{},
+		sources:   map[string]string{},
+	}
+}
+
+func adminStructLevelValidation(sl validator.StructLevel) {
+	admin := sl.Current().Interface().(Admin)
+	if !admin.Enabled || admin.Token != "" {
+		return
+	}
+
+	host, _, err := net.SplitHostPort(admin.URL)
+	if err != nil {
+		return // reported by the hostname_port tag
 	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		sl.ReportError(admin.Token, "Token", "Token", "required_unless_loopback", "")
+	}
+}
 
-	return defaultVal
+// validateListenAddr accepts every address the transports can listen on.
+func validateListenAddr(fl validator.FieldLevel) bool {
+	_, _, err := netaddr.Parse(fl.Field().String())
+	return err == nil
 }
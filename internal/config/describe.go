@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Setting describes a setting for the config commands of the CLI.
+type Setting struct {
+	Key         string // name in the config file, e.g grpc_server.url
+	Env         string
+	Value       string // redacted when sensitive
+	Source      string // default, profile, config file, .env, environment or flag, see Source
+	Constraints string // the validate tag, e.g gte=0
+	Profiles    map[string]string
+	Restart     bool // changes are only applied on a restart
+}
+
+// Source returns what set the setting with the given key, e.g database.dsn:
+// default, profile, config file, .env, environment or flag. The variable is
+// named when it's a _FILE variant and the secret for resolved secret:// references.
+func (c *Config) Source(key string) string {
+	if src, ok := c.sources[key]; ok {
+		return src
+	}
+	return "default"
+}
+
+// Describe lists the effective settings with their sources, sensitive values are
+// redacted.
+func (c *Config) Describe() []Setting {
+	list := describe(c.Redacted())
+	for i := range list {
+		list[i].Source = c.Source(list[i].Key)
+	}
+	return list
+}
+
+// Docs lists every setting with its default value, the values the profiles
+// default to instead and its constraints.
+func Docs() []Setting {
+	list := describe(defaults())
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cfg := defaults()
+		applyProfile(cfg, name)
+		for i, s := range describe(cfg) {
+			if cfg.Source(s.Key) != "profile" {
+				continue
+			}
+			if list[i].Profiles == nil {
+				list[i].Profiles = map[string]string{}
+			}
+			list[i].Profiles[name] = s.Value
+		}
+	}
+	return list
+}
+
+func describe(cfg *Config) []Setting {
+	var list []Setting
+	for _, s := range settings(cfg) {
+		list = append(list, Setting{
+			Key:         s.Key,
+			Env:         s.Env,
+			Value:       formatValue(s.Value),
+			Source:      "default",
+			Constraints: s.Field.Tag.Get("validate"),
+			Restart:     s.Restart,
+		})
+	}
+	return list
+}
+
+// formatValue formats v the way it's written in the environment.
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == fileModeType:
+		return fmt.Sprintf("%#o", v.Uint())
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
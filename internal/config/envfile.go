@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envFiles returns the .env files to load, in increasing precedence. Files given
+// with --env-file, ENV_FILE (comma separated) or LoaderOptions.EnvPath are used as
+// they are, otherwise the first of the working directory and the directory of the
+// executable holding one of the discovered files is used, see discoverEnvFiles.
+func envFiles(flagged []string, path string) []string {
+	if len(flagged) > 0 {
+		return flagged
+	}
+	if val := os.Getenv("ENV_FILE"); val != "" {
+		var files []string
+		for _, file := range strings.Split(val, ",") {
+			if file = strings.TrimSpace(file); file != "" {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
+	if path != "" {
+		return []string{path}
+	}
+
+	var dirs []string
+	if wd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, wd)
+	}
+	if exe, err := os.Executable(); err == nil {
+		if exe, err := filepath.EvalSymlinks(exe); err == nil {
+			dirs = append(dirs, filepath.Dir(exe))
+		}
+	}
+
+	for _, dir := range dirs {
+		if files := discoverEnvFiles(dir); len(files) > 0 {
+			return files
+		}
+	}
+	return nil
+}
+
+// discoverEnvFiles returns the files of dir among .env, .env.<APP_ENV>, .env.local
+// and .env.<APP_ENV>.local, in this order of precedence. APP_ENV is read from the
+// environment, or else from .env.local or .env.
+func discoverEnvFiles(dir string) []string {
+	appEnv := os.Getenv("APP_ENV")
+	if appEnv == "" {
+		appEnv = scanEnvFile(filepath.Join(dir, ".env.local"), "APP_ENV")
+	}
+	if appEnv == "" {
+		appEnv = scanEnvFile(filepath.Join(dir, ".env"), "APP_ENV")
+	}
+
+	names := []string{".env"}
+	if appEnv != "" {
+		names = append(names, ".env."+appEnv)
+	}
+	names = append(names, ".env.local")
+	if appEnv != "" {
+		names = append(names, ".env."+appEnv+".local")
+	}
+
+	var files []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// scanEnvFile returns the value of key in a .env file, it's empty when the file
+// or the key doesn't exist.
+func scanEnvFile(path, key string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	val := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "export ")
+		if v, ok := strings.CutPrefix(line, key+"="); ok {
+			val = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return val
+}
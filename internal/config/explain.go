@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Explain returns a message per problem of an error returned by NewConfig, with
+// the key and the variable of the settings failing validation, e.g
+// "tls.cert_file (TLS_CERT_FILE): is required when tls.enabled is true".
+// Sensitive values are never shown.
+func Explain(err error) []string {
+	var cfg *Config
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		cfg = invalid.cfg
+	}
+	return explain(err, cfg)
+}
+
+// explain returns the messages of err, cfg is the config that failed validation,
+// nil when unknown.
+func explain(err error, cfg *Config) []string {
+	var verrs validator.ValidationErrors
+	var verr *valueError
+	if !errors.As(err, &verrs) && !errors.As(err, &verr) {
+		var msgs []string
+		for _, line := range strings.Split(err.Error(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				msgs = append(msgs, line)
+			}
+		}
+		return msgs
+	}
+
+	switch e := err.(type) {
+	case validator.ValidationErrors:
+		return explainFields(e, cfg)
+	case *valueError:
+		return []string{e.Error()}
+	case interface{ Unwrap() []error }:
+		var msgs []string
+		for _, err := range e.Unwrap() {
+			msgs = append(msgs, explain(err, cfg)...)
+		}
+		return msgs
+	default:
+		return explain(errors.Unwrap(err), cfg)
+	}
+}
+
+// explainFields returns a message per field failing validation of cfg.
+func explainFields(verrs validator.ValidationErrors, cfg *Config) []string {
+	// Settings by their field path, e.g TLS.CertFile.
+	byField := map[string]setting{}
+	root := reflect.TypeOf(Config{})
+	for _, s := range settings(defaults()) {
+		byField[root.Field(s.Section).Name+"."+s.Field.Name] = s
+	}
+
+	msgs := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		path := strings.TrimPrefix(fe.StructNamespace(), root.Name()+".")
+		index := ""
+		if i := strings.IndexByte(path, '['); i >= 0 {
+			path, index = path[:i], path[i:] // an item of a list, e.g Compression.Algorithms[0]
+		}
+
+		s, ok := byField[path]
+		if !ok {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", path, describeRule(fe, "")))
+			continue
+		}
+		section := s.Key[:strings.IndexByte(s.Key, '.')]
+		name, sensitive := s.Env, s.Field.Tag.Get("secret") == "true"
+		if cfg != nil {
+			name, sensitive = cfg.origin(s), sensitive || cfg.sensitive[s.Key]
+		}
+		msg := fmt.Sprintf("%s%s (%s): %s", s.Key, index, name, describeRule(fe, section))
+		if !strings.HasPrefix(fe.Tag(), "required") && !sensitive {
+			msg += fmt.Sprintf(", got %q", formatValue(reflect.ValueOf(fe.Value())))
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// describeRule returns what the rule that failed requires, section is used to
+// name the other settings of the section rules refer to.
+func describeRule(fe validator.FieldError, section string) string {
+	other := func(field string) string {
+		return section + "." + fieldKey(section, field)
+	}
+	param := fe.Param()
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		field, val, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("is required when %s is %s", other(field), val)
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", other(param))
+	case "required_unless_loopback":
+		return fmt.Sprintf("is required when %s isn't a loopback address", other("URL"))
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be at most " + param
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s item(s)", param)
+		}
+		return "must be at least " + param
+	case "gtefield":
+		return "must be at least " + other(param)
+	case "ltefield":
+		return "must be at most " + other(param)
+	case "hostname_port":
+		return "must be a host:port address"
+	case "listen_addr":
+		return "must be a host:port, unix:///path, unix-abstract://name or systemd://[name] address"
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+// fieldKey returns the config key of a field of section, e.g Enabled is enabled.
+func fieldKey(section, field string) string {
+	for _, s := range settings(defaults()) {
+		if strings.HasPrefix(s.Key, section+".") && s.Field.Name == field {
+			return strings.TrimPrefix(s.Key, section+".")
+		}
+	}
+	return field
+}
+
+// origin returns what set the value of s for the messages: its variable, the
+// _FILE variable it was read from or the secret reference it was resolved from.
+func (c *Config) origin(s setting) string {
+	src := c.Source(s.Key)
+	if _, ref, ok := strings.Cut(src, " via "); ok {
+		return ref
+	}
+	if strings.HasSuffix(src, "("+s.Env+"_FILE)") {
+		return s.Env + "_FILE"
+	}
+	return s.Env
+}
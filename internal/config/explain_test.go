@@ -0,0 +1,99 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/secrets"
+)
+
+// TestExplain verifies validation errors are explained with the key, the
+// variable and the rule of every failing setting, secrets are never echoed.
+func TestExplain(t *testing.T) {
+	keys := []string{"APP_ENV", "GRPC_SERVER_URL", "DATABASE_DSN", "DATABASE_DRIVER", "DATABASE_POOL_MAX_IDLE", "DATABASE_POOL_MAX_OPEN", "DATABASE_POOL_MAX_LIFETIME", "LOG_FORMAT"}
+	clearEnv(keys...)
+	defer clearEnv(keys...)
+
+	load := func(args ...string) error {
+		_, err := config.NewConfigWithOptions(config.LoaderOptions{
+			Logger: logger.NewZerologLogger("info", io.Discard),
+			Args:   args,
+		})
+		return err
+	}
+
+	err := load(
+		"--app-env=staging",
+		"--tls-enabled=true",
+		"--log-format=xml",
+		"--grpc-concurrency-limit-min=2000",
+		"--compression-algorithms=gzip,brotli",
+	)
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{
+		"database.dsn (DATABASE_DSN): is required",
+		"tls.cert_file (TLS_CERT_FILE): is required when tls.enabled is true",
+		"tls.key_file (TLS_KEY_FILE): is required when tls.enabled is true",
+		`log.format (LOG_FORMAT): must be one of json, console, got "xml"`,
+		`concurrency_limiter.min_limit (GRPC_CONCURRENCY_LIMIT_MIN): must be at most concurrency_limiter.initial_limit, got "2000"`,
+		`compression.algorithms[1] (COMPRESSION_ALGORITHMS): must be one of zstd, gzip, got "brotli"`,
+	}, config.Explain(err))
+
+	err = load("--app-env=staging", "--admin-enabled=true", "--admin-url=0.0.0.0:6060", "--database-dsn=postgres://localhost/db")
+	require.Error(t, err)
+	for _, msg := range config.Explain(err) {
+		assert.NotContains(t, msg, "got", "no value is shown for a missing setting")
+	}
+
+	// The rules of the profile are explained one per line.
+	err = load("--app-env=production", "--tls-cert-file=cert.pem", "--tls-key-file=key.pem", "--grpc-reflection-enabled=true", "--database-dsn=postgres://localhost/db?sslmode=disable")
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"invalid config for production: database.dsn must not use sslmode=disable",
+		"grpc_server.reflection_enabled must be false",
+	}, config.Explain(err))
+
+	assert.Equal(t, []string{"load env file: open .env: no such file"}, config.Explain(errors.New("load env file: open .env: no such file")))
+}
+
+// TestExplainSensitive verifies values read from a _FILE variable or resolved from
+// a secret reference are never shown, and are named after what they were read from.
+func TestExplainSensitive(t *testing.T) {
+	keys := []string{"APP_ENV", "GRPC_SERVER_URL", "DATABASE_DSN", "DATABASE_DRIVER", "DATABASE_DRIVER_FILE", "DATABASE_POOL_MAX_IDLE", "DATABASE_POOL_MAX_OPEN", "DATABASE_POOL_MAX_LIFETIME"}
+	clearEnv(keys...)
+	defer clearEnv(keys...)
+
+	path := filepath.Join(t.TempDir(), "driver")
+	require.NoError(t, os.WriteFile(path, []byte("hunter2-secret\n"), 0600))
+	os.Setenv("DATABASE_DRIVER_FILE", path)
+
+	_, err := config.NewConfigWithOptions(config.LoaderOptions{
+		Logger: logger.NewZerologLogger("info", io.Discard),
+		Args:   []string{"--database-dsn=postgres://localhost/db"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"database.driver (DATABASE_DRIVER_FILE): must be one of postgres, mysql, sqlite",
+	}, config.Explain(err))
+
+	clearEnv("DATABASE_DRIVER_FILE")
+	os.Setenv("DATABASE_DRIVER", "secret://db-driver")
+
+	_, err = config.NewConfigWithOptions(config.LoaderOptions{
+		Logger:         logger.NewZerologLogger("info", io.Discard),
+		Args:           []string{"--database-dsn=postgres://localhost/db"},
+		SecretProvider: secrets.Static{"db-driver": "hunter2-secret"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"database.driver (secret://db-driver): must be one of postgres, mysql, sqlite",
+	}, config.Explain(err))
+}
@@ -0,0 +1,470 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+)
+
+// setting is a field of a config section, Key is its name in the config file
+// (section.name) and Env the environment variable setting it. Restart is set for
+// the fields, or sections, tagged `reload:"restart"`.
+type setting struct {
+	Key     string
+	Env     string
+	Section int // field index of the section in Config
+	Restart bool
+	Field   reflect.StructField
+	Value   reflect.Value
+}
+
+// settings lists every field of cfg tagged with a config key, in declaration order.
+func settings(cfg *Config) []setting {
+	var list []setting
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		sf := root.Type().Field(i)
+		section := sf.Tag.Get("config")
+		if section == "" || root.Field(i).IsNil() {
+			continue
+		}
+
+		s := root.Field(i).Elem()
+		for j := 0; j < s.NumField(); j++ {
+			f := s.Type().Field(j)
+			name := f.Tag.Get("config")
+			if name == "" {
+				continue
+			}
+			list = append(list, setting{
+				Key:     section + "." + name,
+				Env:     f.Tag.Get("env"),
+				Section: i,
+				Restart: sf.Tag.Get("reload") == "restart" || f.Tag.Get("reload") == "restart",
+				Field:   f,
+				Value:   s.Field(j),
+			})
+		}
+	}
+
+	return list
+}
+
+// loadFile applies a YAML (.yaml, .yml) or TOML (.toml) config file with one
+// table per section, e.g grpc_server.url. Unknown keys are rejected.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	byKey := map[string]setting{}
+	for _, s := range settings(cfg) {
+		byKey[s.Key] = s
+	}
+
+	var errs []string
+	for section, raw := range values {
+		fields, ok := raw.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected a table of settings", section))
+			continue
+		}
+		for name, val := range fields {
+			key := section + "." + name
+			s, ok := byKey[key]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s: unknown setting", key))
+				continue
+			}
+			if err := setValue(s.Value, val); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", key, err))
+				continue
+			}
+			cfg.sources[key] = "config file"
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("config file %s: %s", path, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// loadEnv applies the environment variables. Every variable can also be read
+// from the file named by its _FILE variant, e.g DATABASE_DSN_FILE for mounted
+// secrets, such values are marked sensitive. dotenv holds the variables set by
+// the .env files, to tell them apart in the sources. The values that can't be
+// parsed are returned as invalid, to be reported with the validation errors.
+func loadEnv(cfg *Config, dotenv map[string]bool) (invalid []error, err error) {
+	for _, s := range settings(cfg) {
+		if s.Env == "" {
+			continue
+		}
+
+		name := s.Env
+		val, path := os.Getenv(s.Env), os.Getenv(s.Env+"_FILE")
+		switch {
+		case val != "" && path != "":
+			return nil, fmt.Errorf("both %s and %s_FILE are set, use only one", s.Env, s.Env)
+		case path != "":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read %s_FILE: %w", s.Env, err)
+			}
+			// Editors and kubectl usually leave a trailing newline.
+			val = strings.TrimRight(string(data), "\r\n")
+			name = s.Env + "_FILE"
+			cfg.sensitive[s.Key] = true
+		}
+
+		if val == "" {
+			continue
+		}
+		if err := setValue(s.Value, val); err != nil {
+			invalid = append(invalid, &valueError{
+				Key:    s.Key,
+				Env:    name,
+				Value:  val,
+				Secret: cfg.sensitive[s.Key] || s.Field.Tag.Get("secret") == "true",
+				Type:   s.Value.Type(),
+			})
+			continue
+		}
+
+		source := "environment"
+		if dotenv[name] {
+			source = ".env"
+		}
+		if name != s.Env {
+			source += " (" + name + ")"
+		}
+		cfg.sources[s.Key] = source
+	}
+	return invalid, nil
+}
+
+// valueError is a variable whose value can't be parsed into its setting.
+type valueError struct {
+	Key    string
+	Env    string
+	Value  string
+	Secret bool // the value isn't shown
+	Type   reflect.Type
+}
+
+func (e *valueError) Error() string {
+	msg := fmt.Sprintf("%s (%s): must be %s", e.Key, e.Env, describeType(e.Type))
+	if !e.Secret {
+		msg += fmt.Sprintf(", got %q", e.Value)
+	}
+	return msg
+}
+
+// describeType returns what the values of a setting of type t look like.
+func describeType(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return `a duration, e.g "30s" or "1h30m"`
+	case t == fileModeType:
+		return "an octal file mode, e.g 0660"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int:
+		return "an integer"
+	case reflect.Float64:
+		return "a number"
+	default:
+		return "a " + t.String()
+	}
+}
+
+// envKeys returns the names of the variables set in the environment.
+func envKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		keys[key] = true
+	}
+	return keys
+}
+
+// resolveSecrets replaces the secret://name references of string settings with
+// the values from the provider, or the encrypted file configured in Secrets.
+func resolveSecrets(cfg *Config, provider SecretProvider) error {
+	for _, s := range settings(cfg) {
+		if s.Value.Kind() != reflect.String {
+			continue
+		}
+		name, ok := secrets.ParseRef(s.Value.String())
+		if !ok {
+			continue
+		}
+
+		if provider == nil {
+			if cfg.Secrets.File == "" {
+				return fmt.Errorf("%s references secret %q but no secrets file is configured (SECRETS_FILE)", s.Key, name)
+			}
+			file, err := secrets.NewFile(cfg.Secrets.File, cfg.Secrets.Key)
+			if err != nil {
+				return err
+			}
+			provider = file
+		}
+
+		val, err := provider.Resolve(context.Background(), name)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", s.Key, err)
+		}
+		s.Value.SetString(val)
+		cfg.sensitive[s.Key] = true
+		cfg.sources[s.Key] = cfg.Source(s.Key) + " via " + secrets.Scheme + name
+	}
+	return nil
+}
+
+type flags struct {
+	configFile string
+	envFiles   []string
+	set        *flag.FlagSet
+	settings   map[string]setting
+	sources    map[string]string
+}
+
+// parseFlags parses --config, --env-file and a flag per setting, named after its environment
+// variable: GRPC_SERVER_URL is set with --grpc-server-url. The settings are only
+// applied by apply, so they override the other sources.
+func parseFlags(cfg *Config, args []string) (*flags, error) {
+	f := &flags{
+		set:      flag.NewFlagSet("config", flag.ContinueOnError),
+		settings: map[string]setting{},
+		sources:  cfg.sources,
+	}
+	f.set.StringVar(&f.configFile, "config", "", "YAML or TOML config file, also set with CONFIG_FILE")
+	f.set.Func("env-file", ".env file to load instead of the discovered ones, can be repeated, also set with ENV_FILE", func(path string) error {
+		f.envFiles = append(f.envFiles, path)
+		return nil
+	})
+
+	for _, s := range settings(cfg) {
+		if s.Env == "" {
+			continue
+		}
+		name := flagName(s.Env)
+		f.settings[name] = s
+		f.set.String(name, "", fmt.Sprintf("%s (%s)", s.Key, s.Env))
+	}
+
+	f.set.SetOutput(io.Discard)
+	if err := f.set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			f.set.SetOutput(os.Stderr)
+			f.set.PrintDefaults()
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *flags) apply() error {
+	var err error
+	f.set.Visit(func(fl *flag.Flag) {
+		s, ok := f.settings[fl.Name]
+		if !ok || err != nil {
+			return
+		}
+		if setErr := setValue(s.Value, fl.Value.String()); setErr != nil {
+			err = fmt.Errorf("invalid value for --%s: %w", fl.Name, setErr)
+			return
+		}
+		f.sources[s.Key] = "flag"
+	})
+	return err
+}
+
+// value returns the value of the flag name when it's set.
+func (f *flags) value(name string) (string, bool) {
+	var val string
+	var ok bool
+	f.set.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			val, ok = fl.Value.String(), true
+		}
+	})
+	return val, ok
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// setValue sets v from a value of the config file or a string from the
+// environment or a flag.
+func setValue(v reflect.Value, raw any) error {
+	if s, ok := raw.(string); ok {
+		return setString(v, s)
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return fmt.Errorf("expected a duration string, e.g \"30s\", got %v", raw)
+	case v.Type() == fileModeType:
+		// Octal numbers, e.g 0660 in YAML or 0o660 in TOML, are already decoded.
+		n, ok := toInt(raw)
+		if !ok {
+			return fmt.Errorf("expected a file mode, got %v", raw)
+		}
+		v.SetUint(uint64(n))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean, got %v", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, ok := toInt(raw)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		switch n := raw.(type) {
+		case float64:
+			v.SetFloat(n)
+		default:
+			i, ok := toInt(raw)
+			if !ok {
+				return fmt.Errorf("expected a number, got %v", raw)
+			}
+			v.SetFloat(float64(i))
+		}
+	case reflect.Slice:
+		items, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("expected a list, got %v", raw)
+		}
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a list of strings, got %v", item)
+			}
+			list = append(list, s)
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("expected a string, got %v", raw)
+	}
+	return nil
+}
+
+// setString parses s like the environment variables: comma separated lists and
+// octal file modes.
+func setString(v reflect.Value, s string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Type() == fileModeType:
+		n, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func toInt(raw any) (int64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// profiles change the defaults for an APP_ENV, the config file, the environment
+// and the flags still override them.
+var profiles = map[string]func(cfg *Config){
+	"development": func(cfg *Config) {
+		cfg.App.CheckEnv = true
+		cfg.Log.Format = "console"
+		cfg.GRPCServer.ReflectionEnabled = true
+	},
+	"test": func(cfg *Config) {
+		cfg.Log.Format = "console"
+		// Every connection to an in-memory database opens a new one unless the
+		// cache is shared, a single connection that never expires keeps it alive.
+		cfg.Database.Driver = "sqlite"
+		cfg.Database.DSN = "file::memory:?cache=shared"
+		cfg.Database.PoolMaxIdleConns = 1
+		cfg.Database.PoolMaxOpenConns = 1
+		cfg.Database.PoolConnMaxLifetime = 0
+		cfg.Metrics.Enabled = false
+	},
+	"staging": func(cfg *Config) {
+		cfg.Log.Format = "json"
+	},
+	"production": func(cfg *Config) {
+		cfg.Log.Format = "json"
+		cfg.TLS.Enabled = true
+		cfg.TLS.MinVersion = "1.3"
+	},
+}
+
+// profileOf returns the APP_ENV set with --app-env, the environment or the config
+// file, in this order, as the profile is needed before the file is applied. It's
+// empty when APP_ENV isn't set anywhere, no profile is applied then: the
+// defaults are safe for any environment and development is opt-in.
+func profileOf(flags *flags, configFile string) (string, error) {
+	if val, ok := flags.value("app-env"); ok {
+		return val, nil
+	}
+	if val := os.Getenv("APP_ENV"); val != "" {
+		return val, nil
+	}
+
+	if configFile != "" {
+		probe := defaults()
+		if err := loadFile(probe, configFile); err != nil {
+			return "", err
+		}
+		if probe.App.Env != "" {
+			return probe.App.Env, nil
+		}
+	}
+	return "", nil
+}
+
+// applyProfile sets the defaults of profile, an unknown profile is reported by
+// the validation of App.Env.
+func applyProfile(cfg *Config, profile string) {
+	cfg.App.Env = profile
+	apply, ok := profiles[profile]
+	if !ok {
+		return
+	}
+	apply(cfg)
+
+	base := settings(defaults())
+	for i, s := range settings(cfg) {
+		if s.Key != "app.env" && !reflect.DeepEqual(s.Value.Interface(), base[i].Value.Interface()) {
+			cfg.sources[s.Key] = "profile"
+		}
+	}
+}
+
+// validateProfile applies the rules of the profile on top of the field validation.
+func validateProfile(cfg *Config) error {
+	if cfg.App.Env != "production" {
+		return nil
+	}
+
+	var errs []error
+	if !cfg.TLS.Enabled {
+		errs = append(errs, errors.New("tls.enabled must be true"))
+	}
+	if strings.Contains(cfg.Database.DSN, "sslmode=disable") {
+		errs = append(errs, errors.New("database.dsn must not use sslmode=disable"))
+	}
+	if cfg.GRPCServer.ReflectionEnabled {
+		errs = append(errs, errors.New("grpc_server.reflection_enabled must be false"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config for production: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a deep copy of the config where fields tagged `secret:"true"` are
+// masked, so it's safe to print or serve. For URLs (e.g a DSN) only the password is
+// masked, keeping the host and database visible. Values read from a file or a secret
+// provider are masked entirely.
+func (c *Config) Redacted() *Config {
+	cp := redactValue(reflect.ValueOf(c)).Interface().(*Config)
+	for _, s := range settings(cp) {
+		if c.sensitive[s.Key] && s.Value.Kind() == reflect.String && s.Value.String() != "" {
+			s.Value.SetString(redactedValue)
+		}
+	}
+	return cp
+}
+
+// IsSensitive reports whether the setting with the given key, e.g database.dsn, is
+// a secret or was read from a file or a secret provider.
+func (c *Config) IsSensitive(key string) bool {
+	for _, s := range settings(c) {
+		if s.Key == key {
+			return c.sensitive[key] || s.Field.Tag.Get("secret") == "true"
+		}
+	}
+	return false
+}
+
+func redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Elem().Type())
+		cp.Elem().Set(redactValue(v.Elem()))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Tag.Get("secret") == "true" && f.Type.Kind() == reflect.String {
+				cp.Field(i).SetString(redactString(v.Field(i).String()))
+				continue
+			}
+			cp.Field(i).Set(redactValue(v.Field(i)))
+		}
+		return cp
+	default:
+		return v
+	}
+}
+
+func redactString(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+	}
+
+	return redactedValue
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// foreignEnv are the variables sharing a prefix with the settings that are read
+// by the libraries, e.g grpc-go.
+var foreignEnv = []string{"GRPC_GO_", "GRPC_TRACE", "GRPC_VERBOSITY", "GRPC_DEFAULT_SSL_ROOTS_FILE_PATH", "GRPC_ENFORCE_ALPN_ENABLED"}
+
+// unknownEnv is a variable of the environment sharing a prefix with the settings
+// but matching none, Suggestion is the closest known variable if any.
+type unknownEnv struct {
+	Name       string
+	Suggestion string
+}
+
+// findUnknownEnv returns the variables of env starting with the prefix of a
+// setting, e.g DATABASE_, that don't name a setting, its _FILE variant or a
+// variable of the loader, sorted by name.
+func findUnknownEnv(cfg *Config, env map[string]bool) []unknownEnv {
+	known := map[string]bool{"CONFIG_FILE": true, "ENV_FILE": true}
+	prefixes := map[string]bool{}
+	for _, s := range settings(cfg) {
+		if s.Env == "" {
+			continue
+		}
+		known[s.Env] = true
+		prefix, _, _ := strings.Cut(s.Env, "_")
+		prefixes[prefix+"_"] = true
+	}
+
+	var unknown []unknownEnv
+	for name := range env {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok || !prefixes[prefix+"_"] || known[name] || known[strings.TrimSuffix(name, "_FILE")] || isForeignEnv(name) {
+			continue
+		}
+		unknown = append(unknown, unknownEnv{Name: name, Suggestion: suggestEnv(name, known)})
+	}
+
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Name < unknown[j].Name })
+	return unknown
+}
+
+func isForeignEnv(name string) bool {
+	for _, prefix := range foreignEnv {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestEnv returns the known variable closest to name, or an empty string when
+// none is close enough to be a misspelling.
+func suggestEnv(name string, known map[string]bool) string {
+	base, suffix := name, ""
+	if strings.HasSuffix(name, "_FILE") {
+		base, suffix = strings.TrimSuffix(name, "_FILE"), "_FILE"
+	}
+
+	best, bestDist := "", max(2, len(base)/3)+1
+	for candidate := range known {
+		d := editDistance(base, candidate)
+		if d < bestDist || (best != "" && d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return best + suffix
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+// reloadDebounce groups the events of a single save, editors usually write a
+// file in several steps.
+const reloadDebounce = 100 * time.Millisecond
+
+type WatcherOpts struct {
+	Config *Config // as returned by NewConfig, it's reloaded with the same options
+	Logger logger.Logger
+}
+
+// Watcher reloads the config when its file changes or on SIGHUP. The settings
+// tagged `reload:"restart"` keep their current value, with a warning, and the
+// changes to the others are published to the OnChange subscribers.
+//
+// The environment of a running process doesn't change, so reloads pick up the
+// config file and the values read from files (*_FILE and secret:// references).
+type Watcher struct {
+	current atomic.Pointer[Config]
+	log     logger.Logger
+
+	mu   sync.Mutex // serializes reloads and guards subs
+	subs []subscriber
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type subscriber struct {
+	section int
+	fn      func(prev, next *Config)
+}
+
+func NewWatcher(opts *WatcherOpts) *Watcher {
+	w := &Watcher{log: opts.Logger}
+	w.current.Store(opts.Config)
+	return w
+}
+
+// OnChange calls fn with the previous and the new section of type T, e.g
+// *config.Database, after every reload changing one of its settings.
+func OnChange[T any](w *Watcher, fn func(prev, next *T)) {
+	section := -1
+	root := reflect.TypeOf(Config{})
+	for i := 0; i < root.NumField(); i++ {
+		if root.Field(i).Type == reflect.TypeOf((*T)(nil)) {
+			section = i
+			break
+		}
+	}
+	if section < 0 {
+		panic(fmt.Sprintf("config: %T is not a config section", (*T)(nil)))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, subscriber{section: section, fn: func(prev, next *Config) {
+		fn(reflect.ValueOf(prev).Elem().Field(section).Interface().(*T),
+			reflect.ValueOf(next).Elem().Field(section).Interface().(*T))
+	}})
+}
+
+// Current returns the config as of the last successful reload.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Reload loads the config again and publishes the changes. The current config
+// is kept when the new one is invalid.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev := w.current.Load()
+	next, err := NewConfigWithOptions(prev.loader)
+	if err != nil {
+		return err
+	}
+
+	changed := map[int]bool{}
+	nextSettings := settings(next)
+	for i, s := range settings(prev) {
+		n := nextSettings[i]
+		if reflect.DeepEqual(s.Value.Interface(), n.Value.Interface()) {
+			continue
+		}
+
+		oldVal, newVal := fmt.Sprint(s.Value.Interface()), fmt.Sprint(n.Value.Interface())
+		if prev.IsSensitive(s.Key) || next.IsSensitive(s.Key) {
+			oldVal, newVal = redactedValue, redactedValue
+		}
+		fields := []logger.Field{
+			{Key: "key", Value: s.Key},
+			{Key: "old", Value: oldVal},
+			{Key: "new", Value: newVal},
+		}
+
+		if s.Restart {
+			w.log.Warn("Config change requires a restart, keeping the current value", fields...)
+			n.Value.Set(s.Value)
+			if prev.sensitive[s.Key] {
+				next.sensitive[s.Key] = true
+			} else {
+				delete(next.sensitive, s.Key)
+			}
+			continue
+		}
+
+		w.log.Info("Config changed", fields...)
+		changed[s.Section] = true
+	}
+
+	// Kept values may not agree with the new ones, e.g a limit and its bounds.
+	if err := validateConfig(next); err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	w.current.Store(next)
+	for _, sub := range w.subs {
+		if changed[sub.section] {
+			sub.fn(prev, next)
+		}
+	}
+	return nil
+}
+
+// Start watches the config file, if any, and SIGHUP until Stop is called.
+func (w *Watcher) Start(context.Context) error {
+	var events chan fsnotify.Event
+	var errs chan error
+
+	file := w.current.Load().file
+	var fsw *fsnotify.Watcher
+	if file != "" {
+		var err error
+		fsw, err = fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		// The directory is watched, editors and Kubernetes ConfigMaps replace the
+		// file rather than writing to it.
+		if err := fsw.Add(filepath.Dir(file)); err != nil {
+			fsw.Close()
+			return fmt.Errorf("watch config file: %w", err)
+		}
+		events, errs = fsw.Events, fsw.Errors
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		defer signal.Stop(sig)
+		if fsw != nil {
+			defer fsw.Close()
+		}
+
+		target, _ := filepath.EvalSymlinks(file)
+		debounce := time.NewTimer(0)
+		<-debounce.C
+
+		for {
+			select {
+			case ev := <-events:
+				// The file itself changed, or a symlink to it was swapped.
+				if resolved, _ := filepath.EvalSymlinks(file); filepath.Clean(ev.Name) == filepath.Clean(file) || resolved != target {
+					target = resolved
+					debounce.Reset(reloadDebounce)
+				}
+			case err := <-errs:
+				w.log.Error("Config file watcher failed", logger.Field{Key: "error", Value: err.Error()})
+			case <-sig:
+				w.log.Info("SIGHUP received, reloading config")
+				w.reload()
+			case <-debounce.C:
+				w.log.Info("Config file changed, reloading config", logger.Field{Key: "path", Value: file})
+				w.reload()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops watching, it waits for a reload in progress.
+func (w *Watcher) Stop(context.Context) error {
+	if w.stop == nil {
+		return nil
+	}
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil {
+		w.log.Error("Config reload failed, keeping the current config", logger.Field{Key: "error", Value: err.Error()})
+	}
+}
@@ -0,0 +1,74 @@
+// Package dag orders named nodes so that every node comes after the nodes it
+// depends on, e.g application components or seeders.
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Graph struct {
+	nodes []string
+	deps  map[string][]string
+}
+
+func New() *Graph {
+	return &Graph{deps: map[string][]string{}}
+}
+
+// Add adds a node with the names of the nodes it depends on, they may be added later.
+func (g *Graph) Add(name string, dependsOn ...string) error {
+	if _, ok := g.deps[name]; ok {
+		return fmt.Errorf("%q is already added", name)
+	}
+	g.nodes = append(g.nodes, name)
+	g.deps[name] = dependsOn
+	return nil
+}
+
+// Sort returns the nodes in dependency order. Nodes that don't depend on each
+// other keep the order they were added in, so the result is deterministic.
+func (g *Graph) Sort() ([]string, error) {
+	for _, name := range g.nodes {
+		for _, dep := range g.deps[name] {
+			if _, ok := g.deps[dep]; !ok {
+				return nil, fmt.Errorf("%q depends on unknown %q", name, dep)
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(g.nodes))
+	placed := make(map[string]bool, len(g.nodes))
+	for len(sorted) < len(g.nodes) {
+		progress := false
+		for _, name := range g.nodes {
+			if placed[name] || !g.ready(name, placed) {
+				continue
+			}
+			sorted = append(sorted, name)
+			placed[name] = true
+			progress = true
+		}
+
+		if !progress {
+			var cycle []string
+			for _, name := range g.nodes {
+				if !placed[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between %s", strings.Join(cycle, ", "))
+		}
+	}
+
+	return sorted, nil
+}
+
+func (g *Graph) ready(name string, placed map[string]bool) bool {
+	for _, dep := range g.deps[name] {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package dag_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/dag"
+)
+
+// TestGraph_Sort verifies nodes come after their dependencies and independent
+// nodes keep the order they were added in.
+func TestGraph_Sort(t *testing.T) {
+	g := dag.New()
+	require.NoError(t, g.Add("http", "grpc"))
+	require.NoError(t, g.Add("tracer"))
+	require.NoError(t, g.Add("grpc", "database", "tracer"))
+	require.NoError(t, g.Add("database"))
+	require.NoError(t, g.Add("admin"))
+
+	sorted, err := g.Sort()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tracer", "database", "admin", "grpc", "http"}, sorted)
+}
+
+// TestGraph_SortErrors verifies duplicates, unknown dependencies and cycles are reported.
+func TestGraph_SortErrors(t *testing.T) {
+	g := dag.New()
+	require.NoError(t, g.Add("a"))
+	assert.ErrorContains(t, g.Add("a"), `"a" is already added`)
+
+	g = dag.New()
+	require.NoError(t, g.Add("a", "missing"))
+	_, err := g.Sort()
+	assert.ErrorContains(t, err, `"a" depends on unknown "missing"`)
+
+	g = dag.New()
+	require.NoError(t, g.Add("root"))
+	require.NoError(t, g.Add("a", "b"))
+	require.NoError(t, g.Add("b", "a"))
+	_, err = g.Sort()
+	assert.ErrorContains(t, err, "dependency cycle between a, b")
+}
@@ -53,20 +53,33 @@ func NewDatabase(opts *Opts) (DatabaseService, error) {
 		return nil, fmt.Errorf("failed to connect to %s: %v", driver, err)
 	}
 
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get db instance: %v", err)
+	if err := db.Use(newTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %v", err)
 	}
 
-	sqlDB.SetMaxIdleConns(opts.Config.PoolMaxIdleConns)
-	sqlDB.SetMaxOpenConns(opts.Config.PoolMaxOpenConns)
-	sqlDB.SetConnMaxLifetime(opts.Config.PoolConnMaxLifetime)
+	if err := ConfigurePool(db, opts.Config); err != nil {
+		return nil, err
+	}
 
 	opts.Logger.Info("Database connected", logger.Field{Key: "driver", Value: driver})
 
 	return &Database{db: db, Logger: opts.Logger}, nil
 }
 
+// ConfigurePool applies the pool settings, it's safe to call on a database in use,
+// e.g when the config is reloaded.
+func ConfigurePool(db *gorm.DB, cfg *config.Database) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get db instance: %v", err)
+	}
+
+	sqlDB.SetMaxIdleConns(cfg.PoolMaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.PoolMaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.PoolConnMaxLifetime)
+	return nil
+}
+
 func (d *Database) DB() *gorm.DB {
 	return d.db
 }
@@ -58,3 +58,21 @@ func TestDatabase_Close_InvalidDB(t *testing.T) {
 	err := db.Close()
 	assert.Error(t, err, "expected error when closing nil DB")
 }
+
+// Test ConfigurePool applies the pool settings to an open database
+func TestConfigurePool(t *testing.T) {
+	log := logger.NewZerologLogger("info", io.Discard)
+	db, err := database.NewDatabase(&database.Opts{
+		Config: &config.Database{DSN: ":memory:", Driver: "sqlite", PoolMaxOpenConns: 10},
+		Logger: log,
+	})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	err = database.ConfigurePool(db.DB(), &config.Database{PoolMaxOpenConns: 3})
+	assert.NoError(t, err)
+
+	sqlDB, err := db.DB().DB()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sqlDB.Stats().MaxOpenConnections)
+}
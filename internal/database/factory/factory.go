@@ -0,0 +1,101 @@
+// Package factory builds valid models filled with fake data, for the seeders and
+// the tests. Factories are deterministic: the same seed builds the same models.
+package factory
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultBatchSize is the number of rows inserted per statement by CreateMany.
+const DefaultBatchSize = 1000
+
+// Factory builds models of type T. Its definition fills a model from the faker
+// and seq, the position of the model starting at 1, to keep unique columns
+// unique. Overrides change the built models, e.g to set a relationship:
+//
+//	posts.CreateMany(db, 10, func(p *model.Post) { p.UserID = user.ID })
+type Factory[T any] struct {
+	seq        *sequence // shared by the derived factories, see With
+	define     func(f *Faker, seq int) T
+	overrides  []func(*T)
+	onConflict *clause.OnConflict
+}
+
+type sequence struct {
+	faker *Faker
+	n     int
+}
+
+// New returns a factory of the models built by define, seed makes the fake data
+// reproducible.
+func New[T any](seed uint64, define func(f *Faker, seq int) T) *Factory[T] {
+	return &Factory[T]{seq: &sequence{faker: NewFaker(seed)}, define: define}
+}
+
+// With returns a factory applying overrides to every model it builds, e.g a
+// state like admin users. It shares the sequence and the faker of f.
+func (f *Factory[T]) With(overrides ...func(*T)) *Factory[T] {
+	derived := *f
+	derived.overrides = append(append([]func(*T){}, f.overrides...), overrides...)
+	return &derived
+}
+
+// Make builds a model without saving it.
+func (f *Factory[T]) Make(overrides ...func(*T)) T {
+	f.seq.n++
+	model := f.define(f.seq.faker, f.seq.n)
+	for _, override := range f.overrides {
+		override(&model)
+	}
+	for _, override := range overrides {
+		override(&model)
+	}
+	return model
+}
+
+// MakeMany builds n models without saving them.
+func (f *Factory[T]) MakeMany(n int, overrides ...func(*T)) []T {
+	models := make([]T, n)
+	for i := range models {
+		models[i] = f.Make(overrides...)
+	}
+	return models
+}
+
+// Create builds a model and inserts it, with its associations.
+func (f *Factory[T]) Create(db *gorm.DB, overrides ...func(*T)) (T, error) {
+	model := f.Make(overrides...)
+	err := f.clauses(db).Create(&model).Error
+	return model, err
+}
+
+// CreateMany builds n models and inserts them DefaultBatchSize rows at a time.
+func (f *Factory[T]) CreateMany(db *gorm.DB, n int, overrides ...func(*T)) ([]T, error) {
+	models := f.MakeMany(n, overrides...)
+	if n == 0 {
+		return models, nil
+	}
+	err := f.clauses(db).CreateInBatches(&models, DefaultBatchSize).Error
+	return models, err
+}
+
+// Upsert returns a factory updating the update columns of the rows conflicting
+// on columns instead of failing, so seeders can be run again. It shares the
+// sequence and the faker of f.
+func (f *Factory[T]) Upsert(columns []string, update ...string) *Factory[T] {
+	derived := *f
+	conflict := clause.OnConflict{DoUpdates: clause.AssignmentColumns(update)}
+	for _, name := range columns {
+		conflict.Columns = append(conflict.Columns, clause.Column{Name: name})
+	}
+	derived.onConflict = &conflict
+	return &derived
+}
+
+func (f *Factory[T]) clauses(db *gorm.DB) *gorm.DB {
+	if f.onConflict == nil {
+		return db
+	}
+	return db.Clauses(*f.onConflict)
+}
@@ -0,0 +1,108 @@
+package factory_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/factory"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+// post is a model referencing users, to build relationships.
+type post struct {
+	ID     uint `gorm:"primaryKey"`
+	Title  string
+	UserID uint
+	User   model.User
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := database.NewDatabase(&database.Opts{
+		Config: &config.Database{DSN: filepath.Join(t.TempDir(), "factory.db"), Driver: "sqlite"},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.DB().AutoMigrate(&model.User{}, &post{}))
+	return db.DB()
+}
+
+// TestUsers_Deterministic verifies the same seed builds the same valid users and
+// that emails are unique.
+func TestUsers_Deterministic(t *testing.T) {
+	users := factory.Users(42).MakeMany(1000)
+	assert.Equal(t, users, factory.Users(42).MakeMany(1000))
+	assert.NotEqual(t, users, factory.Users(7).MakeMany(1000))
+
+	emails := map[string]bool{}
+	for _, u := range users {
+		assert.NotEmpty(t, u.Name)
+		assert.LessOrEqual(t, len(u.Name), 25, "users.name is a VARCHAR(25)")
+		assert.Contains(t, u.Email, "@example.")
+		emails[u.Email] = true
+	}
+	assert.Len(t, emails, len(users))
+}
+
+// TestFactory_Overrides verifies overrides, and the ones of derived factories,
+// are applied to the built models and that derived factories share the sequence.
+func TestFactory_Overrides(t *testing.T) {
+	users := factory.Users(1)
+	admins := users.With(func(u *model.User) { u.Name = "Admin" })
+
+	u := users.Make(func(u *model.User) { u.Email = "alice@example.com" })
+	assert.Equal(t, "alice@example.com", u.Email)
+
+	admin := admins.Make()
+	assert.Equal(t, "Admin", admin.Name)
+	assert.Contains(t, admin.Email, ".2@", "the sequence is shared with the derived factory")
+
+	admin = admins.Make(func(u *model.User) { u.Name = "Root" })
+	assert.Equal(t, "Root", admin.Name, "the overrides of Make are applied last")
+	assert.NotEqual(t, "Admin", users.Make().Name)
+}
+
+// TestFactory_CreateMany verifies models are inserted in batches, with their
+// relationships, and that Upsert updates the existing rows.
+func TestFactory_CreateMany(t *testing.T) {
+	db := newTestDB(t)
+
+	users, err := factory.Users(1).CreateMany(db, factory.DefaultBatchSize+5)
+	require.NoError(t, err)
+	assert.NotZero(t, users[len(users)-1].ID)
+
+	var count int64
+	require.NoError(t, db.Model(&model.User{}).Count(&count).Error)
+	assert.EqualValues(t, factory.DefaultBatchSize+5, count)
+
+	// A factory building the related user with another factory.
+	authors := factory.Users(2)
+	posts := factory.New(1, func(f *factory.Faker, seq int) post {
+		return post{Title: f.FirstName() + "'s post", User: authors.Make()}
+	})
+	created, err := posts.Create(db)
+	require.NoError(t, err)
+	assert.NotZero(t, created.UserID, "the user is created with the post")
+
+	// Or an override referencing an existing user.
+	list, err := posts.CreateMany(db, 3, func(p *post) { p.User, p.UserID = model.User{}, users[0].ID })
+	require.NoError(t, err)
+	for _, p := range list {
+		assert.Equal(t, users[0].ID, p.UserID)
+	}
+
+	_, err = factory.Users(1).CreateMany(db, 1)
+	require.Error(t, err, "the emails are taken")
+
+	_, err = factory.Users(1).Upsert([]string{"email"}, "name").CreateMany(db, 10)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&model.User{}).Count(&count).Error)
+	assert.EqualValues(t, factory.DefaultBatchSize+5+1, count, "no user is added")
+}
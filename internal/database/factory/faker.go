@@ -0,0 +1,63 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+var (
+	firstNames = []string{
+		"Alice", "Bob", "Carol", "David", "Emma", "Farid", "Grace", "Hiro", "Ines", "Jamal",
+		"Kavya", "Liam", "Maya", "Noah", "Olga", "Priya", "Quinn", "Rosa", "Sven", "Tara",
+		"Umar", "Vera", "Wei", "Ximena", "Yusuf", "Zoe",
+	}
+	lastNames = []string{
+		"Adams", "Brown", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Haddad", "Ito", "Jones",
+		"Khan", "Lopez", "Muller", "Nguyen", "Okafor", "Patel", "Rossi", "Silva", "Tanaka", "Weber",
+	}
+	emailDomains = []string{"example.com", "example.org", "example.net"}
+)
+
+// Faker generates fake values, the same seed always generates the same values.
+type Faker struct {
+	rand *rand.Rand
+}
+
+func NewFaker(seed uint64) *Faker {
+	return &Faker{rand: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// IntBetween returns a number in [min, max].
+func (f *Faker) IntBetween(min, max int) int {
+	return min + f.rand.IntN(max-min+1)
+}
+
+func (f *Faker) Bool() bool {
+	return f.rand.IntN(2) == 1
+}
+
+func (f *Faker) FirstName() string {
+	return Pick(f, firstNames)
+}
+
+func (f *Faker) LastName() string {
+	return Pick(f, lastNames)
+}
+
+// Name returns a full name, short enough for the 25 characters of users.name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Email returns an address for name made unique by seq, e.g
+// alice.chen.42@example.com.
+func (f *Faker) Email(name string, seq int) string {
+	local := strings.ToLower(strings.ReplaceAll(name, " ", "."))
+	return fmt.Sprintf("%s.%d@%s", local, seq, Pick(f, emailDomains))
+}
+
+// Pick returns a random item of list.
+func Pick[T any](f *Faker, list []T) T {
+	return list[f.rand.IntN(len(list))]
+}
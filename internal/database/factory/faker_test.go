@@ -0,0 +1,27 @@
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/factory"
+)
+
+// TestFaker verifies the values stay in their bounds and depend only on the seed.
+func TestFaker(t *testing.T) {
+	f, same := factory.NewFaker(3), factory.NewFaker(3)
+
+	seen := map[int]bool{}
+	for range 1000 {
+		n := f.IntBetween(5, 7)
+		assert.Equal(t, n, same.IntBetween(5, 7))
+		assert.GreaterOrEqual(t, n, 5)
+		assert.LessOrEqual(t, n, 7)
+		seen[n] = true
+	}
+	assert.Len(t, seen, 3)
+
+	assert.Equal(t, factory.Pick(f, []string{"a", "b", "c"}), factory.Pick(same, []string{"a", "b", "c"}))
+	assert.Regexp(t, `^ada\.lovelace\.3@example\.(com|org|net)$`, f.Email("Ada Lovelace", 3))
+}
@@ -0,0 +1,14 @@
+package factory
+
+import "github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+
+// Users returns a factory of users with unique emails.
+func Users(seed uint64) *Factory[model.User] {
+	return New(seed, func(f *Faker, seq int) model.User {
+		name := f.Name()
+		return model.User{
+			Name:  name,
+			Email: f.Email(name, seq),
+		}
+	})
+}
@@ -1,44 +1,279 @@
 package seeder
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/dag"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// SeederFunc is a seeder, it runs once per database and is then skipped.
+//
+// The checksum recorded when it runs only covers Name and Version, the code of
+// Func can't be hashed. Bump Version whenever Func changes, otherwise the change
+// goes unnoticed: the seeder isn't reported as changed and never runs again.
 type SeederFunc struct {
 	Name string
-	Func func(db *gorm.DB) error
+	// Version must be bumped when Func changes, see above.
+	Version string
+	// DependsOn names the seeders that run before this one, e.g the ones seeding
+	// the tables it references.
+	DependsOn []string
+	// Envs are the APP_ENV values the seeder runs in, every one when empty.
+	Envs []string
+	// Func runs in a transaction, nothing is kept when it fails.
+	Func func(db *gorm.DB, p Params) error
+}
+
+// DefaultCount is the number of rows created by the seeders generating fake data
+// when Params.Count isn't set.
+const DefaultCount = 10
+
+// Params are passed to every seeder.
+type Params struct {
+	// Count is the number of rows the seeders generating fake data create.
+	Count int
+	// Seed makes the fake data reproducible, see the factory package.
+	Seed uint64
 }
 
 var defaultSeeders = []SeederFunc{
-	{Name: "SeedUsers", Func: SeedUsers},
+	{Name: "SeedUsers", Envs: []string{"development", "test"}, Func: SeedUsers},
 	// Add more seeders here
 }
 
+// The states of a seeder in Statuses.
+const (
+	StatePending = "pending"
+	StateRan     = "ran"
+	StateChanged = "changed" // ran with another checksum
+)
+
+// Run records a seeder that ran, seeders that ran are skipped unless forced.
+type Run struct {
+	Name     string    `gorm:"primaryKey;size:255"`
+	Checksum string    `gorm:"size:64;not null"`
+	RanAt    time.Time `gorm:"not null"`
+}
+
+func (Run) TableName() string {
+	return "seeder_runs"
+}
+
+// Status is the state of a seeder, RanAt is zero when it's pending.
+type Status struct {
+	Name  string
+	State string
+	RanAt time.Time
+}
+
 type Opts struct {
 	DB      *gorm.DB
 	Log     logger.Logger
 	Seeders []SeederFunc
+	// Force runs the selected seeders even if they already ran.
+	Force bool
+	// Only selects the seeders to run by name, Except the ones to leave out.
+	Only   []string
+	Except []string
+	// Env is the APP_ENV, seeders not allowed in it are skipped unless IgnoreEnv
+	// is set. When it's empty only the seeders allowed everywhere run.
+	Env       string
+	IgnoreEnv bool
+	Params    Params
 }
 
+// RunAll runs the selected seeders that haven't run yet, after the seeders they
+// depend on, and records them in the seeder_runs table. Each seeder runs in a
+// transaction with its record.
 func RunAll(opts *Opts) error {
 	log := opts.Log
-	seeders := opts.Seeders
-	if len(seeders) == 0 {
-		seeders = defaultSeeders
+	seeders, err := selectSeeders(opts)
+	if err != nil {
+		return err
+	}
+
+	runs, err := loadRuns(opts.DB)
+	if err != nil {
+		return err
 	}
 
+	params := opts.Params
+	if params.Count == 0 {
+		params.Count = DefaultCount
+	}
+
+	skipped := 0
 	for _, s := range seeders {
+		if !allowed(s, opts) {
+			log.Warn("[Seeder] Skipping "+s.Name+", it's not allowed "+envLabel(opts.Env)+", use --ignore-env to run it anyway",
+				logger.Field{Key: "envs", Value: s.Envs})
+			skipped++
+			continue
+		}
+
+		sum := checksum(s)
+		if run, ok := runs[s.Name]; ok && !opts.Force {
+			if run.Checksum != sum {
+				log.Warn("[Seeder] Skipping "+s.Name+", it changed since it ran, use --force to run it again",
+					logger.Field{Key: "ran_at", Value: run.RanAt})
+			} else {
+				log.Info("[Seeder] Skipping "+s.Name+", already ran", logger.Field{Key: "ran_at", Value: run.RanAt})
+			}
+			continue
+		}
+
+		for _, dep := range s.DependsOn {
+			if _, ok := runs[dep]; !ok {
+				return fmt.Errorf("seeder %s depends on %s, which hasn't run", s.Name, dep)
+			}
+		}
+
 		log.Info("[Seeder] Running " + s.Name)
 
-		if err := s.Func(opts.DB); err != nil {
+		run := Run{Name: s.Name, Checksum: sum}
+		err := opts.DB.Transaction(func(tx *gorm.DB) error {
+			if err := s.Func(tx, params); err != nil {
+				return err
+			}
+			run.RanAt = time.Now().UTC()
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&run).Error; err != nil {
+				return fmt.Errorf("record seeder %s: %w", s.Name, err)
+			}
+			return nil
+		})
+		if err != nil {
 			log.Info("[Seeder] Failed "+s.Name, logger.Field{Key: "error", Value: err.Error()})
 			return err
 		}
+		runs[s.Name] = run
 
 		log.Info("[Seeder] Completed " + s.Name)
 	}
 
+	if skipped > 0 && skipped == len(seeders) {
+		log.Warn("[Seeder] No seeder ran, none is allowed " + envLabel(opts.Env) + ", set APP_ENV or use --ignore-env")
+		return nil
+	}
 	log.Info("[Seeder] All seeders completed successfully")
 	return nil
 }
+
+// Statuses returns the state of the selected seeders.
+func Statuses(opts *Opts) ([]Status, error) {
+	seeders, err := selectSeeders(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := loadRuns(opts.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(seeders))
+	for _, s := range seeders {
+		status := Status{Name: s.Name, State: StatePending}
+		if run, ok := runs[s.Name]; ok {
+			status.State, status.RanAt = StateRan, run.RanAt
+			if run.Checksum != checksum(s) {
+				status.State = StateChanged
+			}
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// selectSeeders returns the seeders of opts, or the default ones, in dependency
+// order and filtered by Only and Except. Explicitly selecting a seeder that isn't
+// allowed in the environment is an error.
+func selectSeeders(opts *Opts) ([]SeederFunc, error) {
+	seeders := opts.Seeders
+	if len(seeders) == 0 {
+		seeders = defaultSeeders
+	}
+
+	graph := dag.New()
+	byName := map[string]SeederFunc{}
+	for _, s := range seeders {
+		if err := graph.Add(s.Name, s.DependsOn...); err != nil {
+			return nil, fmt.Errorf("seeder %w", err)
+		}
+		byName[s.Name] = s
+	}
+	order, err := graph.Sort()
+	if err != nil {
+		return nil, fmt.Errorf("seeders: %w", err)
+	}
+
+	only, except := map[string]bool{}, map[string]bool{}
+	for _, names := range []struct {
+		list []string
+		set  map[string]bool
+	}{{opts.Only, only}, {opts.Except, except}} {
+		for _, name := range names.list {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("unknown seeder %q", name)
+			}
+			names.set[name] = true
+		}
+	}
+
+	var selected []SeederFunc
+	for _, name := range order {
+		s := byName[name]
+		if (len(only) > 0 && !only[name]) || except[name] {
+			continue
+		}
+		if only[name] && !allowed(s, opts) {
+			return nil, fmt.Errorf("seeder %s isn't allowed %s, only in %s, use --ignore-env to run it anyway",
+				name, envLabel(opts.Env), strings.Join(s.Envs, ", "))
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+// allowed reports whether s runs in the environment of opts.
+func allowed(s SeederFunc, opts *Opts) bool {
+	return opts.IgnoreEnv || len(s.Envs) == 0 || slices.Contains(s.Envs, opts.Env)
+}
+
+// envLabel names env in the messages, e.g "in APP_ENV=production".
+func envLabel(env string) string {
+	if env == "" {
+		return "without APP_ENV"
+	}
+	return "in APP_ENV=" + env
+}
+
+// loadRuns creates the seeder_runs table if needed and returns the runs by name.
+func loadRuns(db *gorm.DB) (map[string]Run, error) {
+	if err := db.AutoMigrate(&Run{}); err != nil {
+		return nil, fmt.Errorf("create seeder_runs table: %w", err)
+	}
+
+	var list []Run
+	if err := db.Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("load seeder runs: %w", err)
+	}
+
+	runs := make(map[string]Run, len(list))
+	for _, run := range list {
+		runs[run.Name] = run
+	}
+	return runs, nil
+}
+
+func checksum(s SeederFunc) string {
+	sum := sha256.Sum256([]byte(s.Name + "\x00" + s.Version))
+	return hex.EncodeToString(sum[:])
+}
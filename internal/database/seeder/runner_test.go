@@ -1,22 +1,28 @@
 package seeder_test
 
 import (
+	"bytes"
 	"errors"
 	"io"
+	"path/filepath"
 	"testing"
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/seeder"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
 func newTestDB(t *testing.T) *gorm.DB {
 	log := logger.NewZerologLogger("info", io.Discard)
 	db, err := database.NewDatabase(&database.Opts{
-		Config: &config.Database{DSN: ":memory:", Driver: "sqlite"},
+		// Every connection to :memory: opens a new database, the seeder runs
+		// need to outlive the connection recording them.
+		Config: &config.Database{DSN: filepath.Join(t.TempDir(), "seed.db"), Driver: "sqlite"},
 		Logger: log,
 	})
 
@@ -31,7 +37,7 @@ func TestRunAll_Success(t *testing.T) {
 	log := logger.NewZerologLogger("info", io.Discard)
 
 	seeders := []seeder.SeederFunc{
-		{Name: "TestSeeder", Func: func(db *gorm.DB) error {
+		{Name: "TestSeeder", Func: func(db *gorm.DB, _ seeder.Params) error {
 			return nil
 		}},
 	}
@@ -52,7 +58,7 @@ func TestRunAll_Failure(t *testing.T) {
 	log := logger.NewZerologLogger("info", io.Discard)
 
 	seeders := []seeder.SeederFunc{
-		{Name: "FailingSeeder", Func: func(db *gorm.DB) error {
+		{Name: "FailingSeeder", Func: func(db *gorm.DB, _ seeder.Params) error {
 			return errors.New("boom")
 		}},
 	}
@@ -66,3 +72,226 @@ func TestRunAll_Failure(t *testing.T) {
 	assert.Error(t, err, "expected error when a seeder fails")
 	assert.EqualError(t, err, "boom", "expected specific error message to propagate")
 }
+
+// TestRunAll_SkipsSeedersThatRan verifies seeders are recorded and only run once
+// unless forced.
+func TestRunAll_SkipsSeedersThatRan(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	calls := 0
+	seeders := []seeder.SeederFunc{
+		{Name: "CountingSeeder", Func: func(db *gorm.DB, _ seeder.Params) error {
+			calls++
+			return nil
+		}},
+	}
+
+	for range 2 {
+		require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+	}
+	assert.Equal(t, 1, calls, "a seeder that ran is skipped")
+
+	var runs []seeder.Run
+	require.NoError(t, db.Find(&runs).Error)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "CountingSeeder", runs[0].Name)
+	assert.Len(t, runs[0].Checksum, 64)
+
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Force: true}))
+	assert.Equal(t, 2, calls, "--force runs it again")
+}
+
+// TestRunAll_FailureNotRecorded verifies a failing seeder runs again next time.
+func TestRunAll_FailureNotRecorded(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	fail := true
+	seeders := []seeder.SeederFunc{
+		{Name: "FlakySeeder", Func: func(db *gorm.DB, _ seeder.Params) error {
+			if fail {
+				return errors.New("boom")
+			}
+			return nil
+		}},
+	}
+
+	require.Error(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+
+	statuses, err := seeder.Statuses(&seeder.Opts{DB: db, Seeders: seeders})
+	require.NoError(t, err)
+	assert.Equal(t, seeder.StatePending, statuses[0].State)
+
+	fail = false
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+}
+
+// TestRunAll_Selection verifies Only and Except select the seeders to run and
+// that unknown names are rejected.
+func TestRunAll_Selection(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	var ran []string
+	seeders := make([]seeder.SeederFunc, 0, 3)
+	for _, name := range []string{"A", "B", "C"} {
+		seeders = append(seeders, seeder.SeederFunc{Name: name, Func: func(db *gorm.DB, _ seeder.Params) error {
+			ran = append(ran, name)
+			return nil
+		}})
+	}
+
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"C", "A"}}))
+	assert.Equal(t, []string{"A", "C"}, ran, "the seeders keep their order")
+
+	ran = nil
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Except: []string{"B"}, Force: true}))
+	assert.Equal(t, []string{"A", "C"}, ran)
+
+	err := seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"D"}})
+	assert.EqualError(t, err, `unknown seeder "D"`)
+	err = seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Except: []string{"D"}})
+	assert.EqualError(t, err, `unknown seeder "D"`)
+}
+
+// TestStatuses verifies the seeders are reported pending, ran, or changed when
+// their version changed since they ran.
+func TestStatuses(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	noop := func(db *gorm.DB, _ seeder.Params) error { return nil }
+	seeders := []seeder.SeederFunc{
+		{Name: "A", Func: noop},
+		{Name: "B", Version: "1", Func: noop},
+		{Name: "C", Func: noop},
+	}
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Except: []string{"C"}}))
+
+	seeders[1].Version = "2"
+	statuses, err := seeder.Statuses(&seeder.Opts{DB: db, Seeders: seeders})
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+
+	assert.Equal(t, "A", statuses[0].Name)
+	assert.Equal(t, seeder.StateRan, statuses[0].State)
+	assert.False(t, statuses[0].RanAt.IsZero())
+	assert.Equal(t, seeder.StateChanged, statuses[1].State)
+	assert.Equal(t, seeder.StatePending, statuses[2].State)
+	assert.True(t, statuses[2].RanAt.IsZero())
+
+	// A changed seeder is skipped unless forced.
+	calls := 0
+	seeders[1].Func = func(db *gorm.DB, _ seeder.Params) error {
+		calls++
+		return nil
+	}
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"B"}}))
+	assert.Equal(t, 0, calls)
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"B"}, Force: true}))
+	assert.Equal(t, 1, calls)
+
+	statuses, err = seeder.Statuses(&seeder.Opts{DB: db, Seeders: seeders, Only: []string{"B"}})
+	require.NoError(t, err)
+	assert.Equal(t, seeder.StateRan, statuses[0].State)
+}
+
+// TestRunAll_DependencyOrder verifies seeders run after the ones they depend on
+// and that a dependency left out of the run must have run before.
+func TestRunAll_DependencyOrder(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	var ran []string
+	seed := func(name string, dependsOn ...string) seeder.SeederFunc {
+		return seeder.SeederFunc{Name: name, DependsOn: dependsOn, Func: func(db *gorm.DB, _ seeder.Params) error {
+			ran = append(ran, name)
+			return nil
+		}}
+	}
+	seeders := []seeder.SeederFunc{seed("Posts", "Users"), seed("Comments", "Posts", "Users"), seed("Users")}
+
+	err := seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"Posts"}})
+	assert.EqualError(t, err, "seeder Posts depends on Users, which hasn't run")
+
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+	assert.Equal(t, []string{"Users", "Posts", "Comments"}, ran)
+
+	ran = nil
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Only: []string{"Comments"}, Force: true}))
+	assert.Equal(t, []string{"Comments"}, ran, "dependencies that ran aren't run again")
+
+	err = seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: []seeder.SeederFunc{seed("A", "B"), seed("B", "A")}})
+	assert.ErrorContains(t, err, "dependency cycle")
+	err = seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: []seeder.SeederFunc{seed("A", "Missing")}})
+	assert.ErrorContains(t, err, `"A" depends on unknown "Missing"`)
+}
+
+// TestRunAll_Transaction verifies a failing seeder leaves no data behind.
+func TestRunAll_Transaction(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+	require.NoError(t, db.AutoMigrate(&model.User{}))
+
+	seeders := []seeder.SeederFunc{
+		{Name: "HalfSeeder", Func: func(db *gorm.DB, _ seeder.Params) error {
+			if err := db.Create(&model.User{Name: "Alice", Email: "alice@example.com"}).Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		}},
+	}
+
+	err := seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders})
+	assert.EqualError(t, err, "boom")
+
+	var count int64
+	require.NoError(t, db.Model(&model.User{}).Count(&count).Error)
+	assert.Zero(t, count, "the rows of the failed seeder are rolled back")
+}
+
+// TestRunAll_Envs verifies seeders only run in the environments they allow,
+// unless IgnoreEnv is set.
+func TestRunAll_Envs(t *testing.T) {
+	db := newTestDB(t)
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	var ran []string
+	seed := func(name string, envs ...string) seeder.SeederFunc {
+		return seeder.SeederFunc{Name: name, Envs: envs, Func: func(db *gorm.DB, _ seeder.Params) error {
+			ran = append(ran, name)
+			return nil
+		}}
+	}
+	seeders := []seeder.SeederFunc{seed("Fixtures", "development", "test"), seed("Roles")}
+
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+	assert.Equal(t, []string{"Roles"}, ran, "development fixtures are skipped without APP_ENV")
+
+	ran = nil
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Env: "production", Force: true}))
+	assert.Equal(t, []string{"Roles"}, ran, "development fixtures are skipped in production")
+
+	err := seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Env: "production", Only: []string{"Fixtures"}})
+	assert.ErrorContains(t, err, "seeder Fixtures isn't allowed in APP_ENV=production")
+
+	ran = nil
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders, Env: "production", IgnoreEnv: true}))
+	assert.Equal(t, []string{"Fixtures"}, ran, "Roles already ran")
+}
+
+// TestRunAll_NoneAllowed verifies a warning is logged instead of success when the
+// environment skips every seeder, e.g development fixtures without APP_ENV.
+func TestRunAll_NoneAllowed(t *testing.T) {
+	db := newTestDB(t)
+	var buf bytes.Buffer
+	log := logger.NewZerologLogger("info", &buf)
+
+	seeders := []seeder.SeederFunc{{Name: "Fixtures", Envs: []string{"development"}, Func: func(*gorm.DB, seeder.Params) error {
+		return nil
+	}}}
+	require.NoError(t, seeder.RunAll(&seeder.Opts{DB: db, Log: log, Seeders: seeders}))
+	assert.Contains(t, buf.String(), "No seeder ran, none is allowed without APP_ENV")
+	assert.NotContains(t, buf.String(), "completed successfully")
+}
@@ -1,19 +1,15 @@
 package seeder
 
 import (
-	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/factory"
 	"gorm.io/gorm"
 )
 
-func SeedUsers(db *gorm.DB) error {
-	users := []model.User{
-		{Name: "Alice", Email: "alice@example.com"},
-		{Name: "Bob", Email: "bob@example.com"},
-	}
-	for _, u := range users {
-		if err := db.Create(&u).Error; err != nil {
-			return err
-		}
-	}
-	return nil
+// SeedUsers creates p.Count fake users, existing ones are updated by email so it
+// can be run again with --force.
+func SeedUsers(db *gorm.DB, p Params) error {
+	_, err := factory.Users(p.Seed).
+		Upsert([]string{"email"}, "name", "updated_at").
+		CreateMany(db, p.Count)
+	return err
 }
@@ -0,0 +1,25 @@
+package seeder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/seeder"
+)
+
+// TestSeedUsers verifies Count users are created and that running the seeder
+// again with the same seed updates them instead of failing.
+func TestSeedUsers(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.AutoMigrate(&model.User{}))
+
+	require.NoError(t, seeder.SeedUsers(db, seeder.Params{Count: 50, Seed: 1}))
+	require.NoError(t, seeder.SeedUsers(db, seeder.Params{Count: 60, Seed: 1}))
+
+	var count int64
+	require.NoError(t, db.Model(&model.User{}).Count(&count).Error)
+	assert.EqualValues(t, 60, count)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "otel:span"
+
+// tracingPlugin creates a span for every GORM operation, it's a child of the span
+// in the statement context (set with db.WithContext) so queries show up under the
+// RPC that issued them.
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+func newTracingPlugin() *tracingPlugin {
+	return &tracingPlugin{
+		tracer: otel.Tracer("github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"),
+	}
+}
+
+func (p *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name   string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("tracing:before_"+h.name, p.before(h.name)); err != nil {
+			return err
+		}
+		if err := h.after("tracing:after_"+h.name, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := p.tracer.Start(tx.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *tracingPlugin) after(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSystemNameKey.String(tx.Dialector.Name()),
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		semconv.DBCollectionName(tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	if err := tx.Error; err != nil && err != gorm.ErrRecordNotFound {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
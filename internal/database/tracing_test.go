@@ -0,0 +1,59 @@
+package database_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+// TestTracingPlugin verifies GORM queries are recorded as child spans of the
+// span in the query context.
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	original := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(original)
+
+	db, err := database.NewDatabase(&database.Opts{
+		Config: &config.Database{DSN: ":memory:", Driver: "sqlite", PoolMaxIdleConns: 1, PoolMaxOpenConns: 1},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, db.DB().AutoMigrate(&model.User{}))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	var user model.User
+	_ = db.DB().WithContext(ctx).First(&user, 1).Error
+	parent.End()
+
+	var query sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "gorm.query" {
+			query = s
+		}
+	}
+	require.NotNil(t, query, "expected a gorm.query span")
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+
+	attrs := map[string]string{}
+	for _, kv := range query.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "sqlite", attrs["db.system.name"])
+	assert.Equal(t, "users", attrs["db.collection.name"])
+	assert.Contains(t, attrs["db.query.text"], "SELECT")
+}
@@ -1,10 +1,13 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"os"
+	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger interface {
@@ -14,6 +17,7 @@ type Logger interface {
 	Error(msg string, fields ...Field)
 	Fatal(msg string, fields ...Field)
 	Panic(msg string, fields ...Field)
+	WithContext(ctx context.Context) Logger
 }
 
 type Field struct {
@@ -40,6 +44,41 @@ func NewZerologLogger(level string, out io.Writer) *ZerologLogger {
 	return &ZerologLogger{log: l}
 }
 
+// NewWriter returns out for the json format, or a writer printing colored and
+// human readable entries to out for the console format, e.g in development.
+func NewWriter(format string, out io.Writer) io.Writer {
+	if format != "console" {
+		return out
+	}
+	return zerolog.ConsoleWriter{Out: out, TimeFormat: time.TimeOnly}
+}
+
+// SetLevel changes the level of every logger, e.g when the config is reloaded.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(lvl)
+	return nil
+}
+
+// WithContext returns a logger that adds the trace_id and span_id of the span
+// in ctx to every entry. The logger is returned as is if ctx carries no span.
+func (l *ZerologLogger) WithContext(ctx context.Context) Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+
+	return &ZerologLogger{
+		log: l.log.With().
+			Str("trace_id", sc.TraceID().String()).
+			Str("span_id", sc.SpanID().String()).
+			Logger(),
+	}
+}
+
 func (l *ZerologLogger) Info(msg string, fields ...Field) {
 	e := l.log.Info()
 	for _, f := range fields {
@@ -2,11 +2,13 @@ package logger_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
 )
@@ -55,6 +57,36 @@ func TestNewZerologLogger_DebugLevel(t *testing.T) {
 	assert.Equal(t, float64(123), entry["count"]) // JSON numbers decode as float64
 }
 
+// TestSetLevel verifies the level of existing loggers can be changed.
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewZerologLogger("info", &buf)
+
+	l.Debug("hidden")
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, logger.SetLevel("debug"))
+	defer logger.SetLevel("info")
+
+	l.Debug("shown")
+	assert.Equal(t, "shown", parseLog(t, &buf)["message"])
+
+	assert.Error(t, logger.SetLevel("loud"))
+}
+
+// TestNewWriter verifies the console format is human readable and json is kept as is.
+func TestNewWriter(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Same(t, &buf, logger.NewWriter("json", &buf))
+
+	l := logger.NewZerologLogger("info", logger.NewWriter("console", &buf))
+	l.Info("hello world", logger.Field{Key: "foo", Value: "bar"})
+
+	assert.Contains(t, buf.String(), "hello world")
+	assert.Contains(t, buf.String(), "foo=")
+	assert.False(t, json.Valid(buf.Bytes()))
+}
+
 // TestLogger_Info verifies that Info messages appear with the correct level and message.
 func TestLogger_Info(t *testing.T) {
 	var buf bytes.Buffer
@@ -121,3 +153,30 @@ func TestLogger_Panic(t *testing.T) {
 	assert.Equal(t, "panic message", entry["message"])
 	assert.Equal(t, true, entry["boom"])
 }
+
+// TestLogger_WithContext verifies trace and span ids of the span in the context
+// are added to log entries, and that a context without a span adds nothing.
+func TestLogger_WithContext(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	var buf bytes.Buffer
+	l := logger.NewZerologLogger("info", &buf)
+
+	l.WithContext(ctx).Info("traced")
+
+	entry := parseLog(t, &buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", entry["span_id"])
+
+	buf.Reset()
+	l.WithContext(context.Background()).Info("untraced")
+
+	entry = parseLog(t, &buf)
+	assert.NotContains(t, entry, "trace_id")
+}
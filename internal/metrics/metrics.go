@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/buildinfo"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+)
+
+type Metrics struct {
+	Registry            *prometheus.Registry
+	GRPCRequestsTotal   *prometheus.CounterVec
+	GRPCRequestDuration *prometheus.HistogramVec
+}
+
+type Opts struct {
+	Database   database.DatabaseService // optional, exposes connection pool stats when set
+	Collectors []prometheus.Collector   // service specific collectors
+}
+
+func NewMetrics(opts *Opts) (*Metrics, error) {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		Registry: registry,
+		GRPCRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, regardless of success or failure.",
+		}, []string{"method", "code"}),
+		GRPCRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Histogram of response latency (seconds) of RPCs handled by the server.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+
+	info := buildinfo.Get()
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "app_build_info",
+		Help: "A metric with a constant '1' value labeled by version, revision and go version the service was built with.",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"revision":   info.Revision,
+			"go_version": info.GoVersion,
+		},
+	})
+	buildInfo.Set(1)
+
+	collectorsToRegister := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		buildInfo,
+		m.GRPCRequestsTotal,
+		m.GRPCRequestDuration,
+	}
+
+	if opts.Database != nil {
+		sqlDB, err := opts.Database.DB().DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get db instance: %v", err)
+		}
+		collectorsToRegister = append(collectorsToRegister, collectors.NewDBStatsCollector(sqlDB, opts.Database.DB().Name()))
+	}
+
+	collectorsToRegister = append(collectorsToRegister, opts.Collectors...)
+
+	if err := m.Register(collectorsToRegister...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Register adds service specific collectors to the registry exposed on /metrics.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.Registry.Register(c); err != nil {
+			return fmt.Errorf("failed to register metrics collector: %w", err)
+		}
+	}
+	return nil
+}
+
+type ConcurrencyLimiter interface {
+	Limit() int
+	Inflight() int
+}
+
+// RegisterConcurrencyLimiter exposes the current limit and in-flight count of an
+// adaptive concurrency limiter.
+func (m *Metrics) RegisterConcurrencyLimiter(l ConcurrencyLimiter) error {
+	return m.Register(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "grpc_server_concurrency_limit",
+			Help: "Current adaptive concurrency limit of the gRPC server.",
+		}, func() float64 { return float64(l.Limit()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "grpc_server_concurrency_inflight",
+			Help: "Number of RPCs currently holding a concurrency limiter slot.",
+		}, func() float64 { return float64(l.Inflight()) }),
+	)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
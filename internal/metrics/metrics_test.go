@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
+)
+
+type fakeLimiter struct{}
+
+func (fakeLimiter) Limit() int    { return 42 }
+func (fakeLimiter) Inflight() int { return 7 }
+
+// scrape performs a GET against the metrics handler and returns the body.
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// TestNewMetrics_DefaultCollectors verifies runtime, process, build info and
+// database pool metrics are exposed.
+func TestNewMetrics_DefaultCollectors(t *testing.T) {
+	db, err := database.NewDatabase(&database.Opts{
+		Config: &config.Database{DSN: ":memory:", Driver: "sqlite"},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := metrics.NewMetrics(&metrics.Opts{Database: db})
+	require.NoError(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "go_goroutines")
+	assert.Contains(t, body, "app_build_info")
+	assert.Contains(t, body, "go_sql_max_open_connections")
+}
+
+// TestMetrics_Register verifies services can expose their own collectors and
+// that duplicate registrations are rejected.
+func TestMetrics_Register(t *testing.T) {
+	m, err := metrics.NewMetrics(&metrics.Opts{})
+	require.NoError(t, err)
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "users_created_total",
+		Help: "Total number of users created.",
+	})
+	require.NoError(t, m.Register(counter))
+	counter.Inc()
+
+	assert.Contains(t, scrape(t, m), "users_created_total 1")
+	assert.Error(t, m.Register(counter))
+}
+
+// TestMetrics_RegisterConcurrencyLimiter verifies the limiter gauges report
+// the limiter's current values.
+func TestMetrics_RegisterConcurrencyLimiter(t *testing.T) {
+	m, err := metrics.NewMetrics(&metrics.Opts{})
+	require.NoError(t, err)
+
+	require.NoError(t, m.RegisterConcurrencyLimiter(fakeLimiter{}))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "grpc_server_concurrency_limit 42")
+	assert.Contains(t, body, "grpc_server_concurrency_inflight 7")
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+type ServerOpts struct {
+	Config  *config.Metrics
+	Logger  logger.Logger
+	Metrics *Metrics
+}
+
+type Server struct {
+	Server *http.Server
+	Config *config.Metrics
+	Logger logger.Logger
+}
+
+func NewServer(opts *ServerOpts) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", opts.Metrics.Handler())
+
+	return &Server{
+		Server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		Config: opts.Config,
+		Logger: opts.Logger,
+	}
+}
+
+func (s *Server) ServeListener(listener net.Listener) error {
+	s.Logger.Info("Metrics server started", logger.Field{Key: "address", Value: listener.Addr().String()})
+	if err := s.Server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.Logger.Error("Metrics server failed", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Serve() error {
+	url := s.Config.URL
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		s.Logger.Error("Failed to create metrics listener",
+			logger.Field{Key: "address", Value: url},
+			logger.Field{Key: "error", Value: err.Error()},
+		)
+		return err
+	}
+	return s.ServeListener(listener)
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
@@ -0,0 +1,53 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
+)
+
+// TestServer_ServeListener verifies /metrics is served on the listener and the
+// server shuts down cleanly.
+func TestServer_ServeListener(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewZerologLogger("info", &buf)
+
+	m, err := metrics.NewMetrics(&metrics.Opts{})
+	require.NoError(t, err)
+
+	srv := metrics.NewServer(&metrics.ServerOpts{
+		Config:  &config.Metrics{Enabled: true},
+		Logger:  log,
+		Metrics: m,
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ServeListener(lis)
+	}()
+
+	resp, err := http.Get("http://" + lis.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "app_build_info")
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	require.NoError(t, <-errCh)
+	assert.Contains(t, buf.String(), "Metrics server started")
+}
@@ -0,0 +1,58 @@
+// Package netaddr parses the listen addresses of the config, without depending
+// on the transports listening on them.
+package netaddr
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	NetworkTCP     = "tcp"
+	NetworkUnix    = "unix"
+	NetworkSystemd = "systemd"
+)
+
+// Parse splits a listen address into its network and address:
+//
+//	host:port              TCP
+//	unix:///run/svc.sock   unix domain socket file
+//	unix-abstract://name   Linux abstract namespace socket (no file)
+//	systemd://[name]       socket inherited from systemd, picked by FileDescriptorName
+func Parse(address string) (network, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix-abstract:"):
+		name := trimScheme(address, "unix-abstract:")
+		if name == "" {
+			return "", "", fmt.Errorf("invalid address %q: missing socket name", address)
+		}
+		return NetworkUnix, "@" + name, nil
+	case strings.HasPrefix(address, "unix:"):
+		path := trimScheme(address, "unix:")
+		if path == "" {
+			return "", "", fmt.Errorf("invalid address %q: missing socket path", address)
+		}
+		return NetworkUnix, path, nil
+	case strings.HasPrefix(address, "systemd:"):
+		return NetworkSystemd, trimScheme(address, "systemd:"), nil
+	}
+
+	if strings.Contains(address, "://") {
+		return "", "", fmt.Errorf("invalid address %q: unsupported scheme", address)
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", "", fmt.Errorf("invalid address %q: invalid port", address)
+	}
+	return NetworkTCP, address, nil
+}
+
+// trimScheme removes the scheme and the optional "//" following it.
+func trimScheme(address, scheme string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(address, scheme), "//")
+}
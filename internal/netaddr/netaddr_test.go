@@ -0,0 +1,46 @@
+package netaddr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/netaddr"
+)
+
+// TestParse verifies every supported address form and rejects unknown ones.
+func TestParse(t *testing.T) {
+	cases := []struct {
+		address string
+		network string
+		addr    string
+		wantErr bool
+	}{
+		{address: ":5000", network: "tcp", addr: ":5000"},
+		{address: "0.0.0.0:5000", network: "tcp", addr: "0.0.0.0:5000"},
+		{address: "unix:///run/svc.sock", network: "unix", addr: "/run/svc.sock"},
+		{address: "unix:svc.sock", network: "unix", addr: "svc.sock"},
+		{address: "unix-abstract://svc", network: "unix", addr: "@svc"},
+		{address: "systemd://", network: "systemd", addr: ""},
+		{address: "systemd://grpc", network: "systemd", addr: "grpc"},
+		{address: "unix://", wantErr: true},
+		{address: "unix-abstract://", wantErr: true},
+		{address: "tcp://localhost:5000", wantErr: true},
+		{address: "localhost", wantErr: true},
+		{address: "localhost:http", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.address, func(t *testing.T) {
+			network, addr, err := netaddr.Parse(c.address)
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.network, network)
+			assert.Equal(t, c.addr, addr)
+		})
+	}
+}
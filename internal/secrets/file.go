@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const keySize = 32 // AES-256
+
+// File resolves secrets from a JSON object of names and values encrypted with
+// AES-256-GCM, the file holds the nonce followed by the ciphertext.
+type File struct {
+	secrets map[string]string
+}
+
+// NewFile decrypts the file at path with a base64 encoded 32 byte key, see GenerateKey.
+func NewFile(path, key string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets file: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("secrets file is truncated")
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("decrypt secrets file: wrong key or corrupted file")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("decode secrets file: %w", err)
+	}
+	return &File{secrets: secrets}, nil
+}
+
+func (f *File) Resolve(ctx context.Context, name string) (string, error) {
+	return Static(f.secrets).Resolve(ctx, name)
+}
+
+// Encrypt returns the content of a secrets file holding secrets.
+func Encrypt(key string, secrets map[string]string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// GenerateKey returns a random base64 encoded key.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func newGCM(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != keySize {
+		return nil, fmt.Errorf("secrets key must be %d bytes encoded in base64", keySize)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,33 @@
+// Package secrets provides the providers resolving secret://name references in
+// the config: an encrypted file for local and single host deployments and a
+// static map for tests.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Scheme prefixes the config values resolved by a provider, e.g secret://db-dsn.
+const Scheme = "secret://"
+
+// ParseRef returns the secret name of a secret://name reference.
+func ParseRef(value string) (string, bool) {
+	name, ok := strings.CutPrefix(value, Scheme)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// Static resolves secrets from a map, e.g in tests.
+type Static map[string]string
+
+func (s Static) Resolve(_ context.Context, name string) (string, error) {
+	val, ok := s[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found", name)
+	}
+	return val, nil
+}
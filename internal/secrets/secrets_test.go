@@ -0,0 +1,35 @@
+package secrets_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/secrets"
+)
+
+// TestParseRef verifies only secret:// values with a name are references.
+func TestParseRef(t *testing.T) {
+	name, ok := secrets.ParseRef("secret://db-dsn")
+	assert.True(t, ok)
+	assert.Equal(t, "db-dsn", name)
+
+	for _, value := range []string{"secret://", "postgres://localhost", "db-dsn", ""} {
+		_, ok := secrets.ParseRef(value)
+		assert.False(t, ok, value)
+	}
+}
+
+// TestStatic verifies known secrets are resolved and unknown ones reported.
+func TestStatic(t *testing.T) {
+	p := secrets.Static{"db-dsn": "postgres://localhost"}
+
+	val, err := p.Resolve(context.Background(), "db-dsn")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://localhost", val)
+
+	_, err = p.Resolve(context.Background(), "missing")
+	assert.ErrorContains(t, err, `secret "missing" not found`)
+}
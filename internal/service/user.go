@@ -5,9 +5,13 @@ import (
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"gorm.io/gorm"
 )
 
+var tracer = otel.Tracer("github.com/sagarmaheshwary/go-microservice-boilerplate/internal/service")
+
 type UserService interface {
 	FindByID(ctx context.Context, id uint) (*model.User, error)
 }
@@ -21,9 +25,14 @@ func NewUserService(db database.DatabaseService) UserService {
 }
 
 func (s *userService) FindByID(ctx context.Context, id uint) (*model.User, error) {
+	ctx, span := tracer.Start(ctx, "UserService.FindByID")
+	defer span.End()
+
 	var user model.User
 	err := s.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 	return &user, nil
@@ -0,0 +1,108 @@
+// Package shutdown runs the graceful shutdown sequence: health checks are failed
+// first, load balancers get a pre-stop delay to notice, then components are
+// stopped in the reverse order they were started, each within a drain timeout.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+)
+
+type Opts struct {
+	Config *config.Shutdown
+	Logger logger.Logger
+}
+
+type step struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+type Sequence struct {
+	Config  *config.Shutdown
+	Logger  logger.Logger
+	preStop []step
+	steps   []step
+}
+
+func NewSequence(opts *Opts) *Sequence {
+	return &Sequence{
+		Config: opts.Config,
+		Logger: opts.Logger,
+	}
+}
+
+// OnPreStop registers a function run before anything is stopped, e.g to report
+// the instance as not serving while it still answers requests.
+func (s *Sequence) OnPreStop(name string, fn func()) {
+	s.preStop = append(s.preStop, step{name: name, stop: func(context.Context) error {
+		fn()
+		return nil
+	}})
+}
+
+// Add registers a started component, components are stopped in reverse order so
+// they are added right after being started. stop gets a context that is done once
+// the drain timeout elapsed, it should then cancel whatever is still in flight.
+func (s *Sequence) Add(name string, stop func(ctx context.Context) error) {
+	s.steps = append(s.steps, step{name: name, stop: stop})
+}
+
+// Run fails the health checks, waits for the pre-stop delay and drains.
+func (s *Sequence) Run() error {
+	s.PreStop()
+	return s.Drain()
+}
+
+// PreStop runs the pre-stop functions and waits for the configured delay.
+func (s *Sequence) PreStop() {
+	s.Logger.Info("Shutdown phase started", logger.Field{Key: "phase", Value: "pre-stop"})
+	for _, p := range s.preStop {
+		_ = p.stop(context.Background())
+		s.Logger.Info("Pre-stop hook done", logger.Field{Key: "name", Value: p.name})
+	}
+
+	if delay := s.Config.PreStopDelay; delay > 0 {
+		s.Logger.Info("Waiting for load balancers before draining", logger.Field{Key: "delay", Value: delay.String()})
+		time.Sleep(delay)
+	}
+}
+
+// Drain stops every component in reverse start order. A component that fails or
+// times out doesn't keep the others from being stopped.
+func (s *Sequence) Drain() error {
+	s.Logger.Info("Shutdown phase started",
+		logger.Field{Key: "phase", Value: "drain"},
+		logger.Field{Key: "timeout", Value: s.Config.DrainTimeout.String()},
+	)
+
+	var errs []error
+	for i := len(s.steps) - 1; i >= 0; i-- {
+		st := s.steps[i]
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.Config.DrainTimeout)
+		err := st.stop(ctx)
+		cancel()
+
+		elapsed := logger.Field{Key: "duration", Value: time.Since(start).String()}
+		if err != nil {
+			s.Logger.Error("Failed to stop component",
+				logger.Field{Key: "name", Value: st.name},
+				logger.Field{Key: "error", Value: err.Error()},
+				elapsed,
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", st.name, err))
+			continue
+		}
+		s.Logger.Info("Component stopped", logger.Field{Key: "name", Value: st.name}, elapsed)
+	}
+
+	s.Logger.Info("Shutdown phase completed", logger.Field{Key: "phase", Value: "drain"})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package shutdown_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/shutdown"
+)
+
+// TestSequence_Run verifies the pre-stop hooks run and the delay elapses before
+// components are stopped, in the reverse order they were added.
+func TestSequence_Run(t *testing.T) {
+	var buf bytes.Buffer
+	seq := shutdown.NewSequence(&shutdown.Opts{
+		Config: &config.Shutdown{PreStopDelay: 50 * time.Millisecond, DrainTimeout: time.Second},
+		Logger: logger.NewZerologLogger("info", &buf),
+	})
+
+	var order []string
+	var notServing time.Time
+	seq.OnPreStop("health", func() {
+		notServing = time.Now()
+		order = append(order, "health")
+	})
+	for _, name := range []string{"database", "grpc server", "http server"} {
+		seq.Add(name, func(context.Context) error {
+			if name == "http server" {
+				assert.GreaterOrEqual(t, time.Since(notServing), 50*time.Millisecond, "draining must wait for the pre-stop delay")
+			}
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	require.NoError(t, seq.Run())
+	assert.Equal(t, []string{"health", "http server", "grpc server", "database"}, order)
+	assert.Contains(t, buf.String(), `"phase":"pre-stop"`)
+	assert.Contains(t, buf.String(), `"phase":"drain"`)
+}
+
+// TestSequence_Drain verifies each component gets a context bounded by the drain
+// timeout and a failing component doesn't keep the others from being stopped.
+func TestSequence_Drain(t *testing.T) {
+	seq := shutdown.NewSequence(&shutdown.Opts{
+		Config: &config.Shutdown{DrainTimeout: 50 * time.Millisecond},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+
+	closed := false
+	seq.Add("database", func(context.Context) error {
+		closed = true
+		return nil
+	})
+	seq.Add("grpc server", func(ctx context.Context) error {
+		<-ctx.Done() // in-flight requests outlive the drain timeout
+		return ctx.Err()
+	})
+	seq.Add("broken", func(context.Context) error {
+		return errors.New("boom")
+	})
+
+	err := seq.Drain()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "broken: boom")
+	assert.True(t, closed)
+}
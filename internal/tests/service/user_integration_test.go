@@ -27,3 +27,17 @@ func TestUserService_FindByID(t *testing.T) {
 	assert.Equal(t, "Alice", got.Name)
 	assert.Equal(t, "alice@example.com", got.Email)
 }
+
+func TestUserService_FindByID_Factory(t *testing.T) {
+	db := testutils.SetupPostgres(t)
+
+	users := testutils.CreateUsers(t, db.DB(), 100)
+
+	userService := service.NewUserService(db)
+
+	got, err := userService.FindByID(context.Background(), users[41].ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, users[41].Name, got.Name)
+	assert.Equal(t, users[41].Email, got.Email)
+}
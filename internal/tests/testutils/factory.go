@@ -0,0 +1,21 @@
+package testutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/factory"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+)
+
+// CreateUsers inserts n fake users, the same for every run of the test, with the
+// overrides applied.
+func CreateUsers(t *testing.T, db *gorm.DB, n int, overrides ...func(*model.User)) []model.User {
+	t.Helper()
+
+	users, err := factory.Users(1).CreateMany(db, n, overrides...)
+	require.NoError(t, err)
+	return users
+}
@@ -0,0 +1,51 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tests/testutils"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tlsconfig"
+)
+
+// TestNew_Disabled verifies no TLS config is returned when TLS is disabled.
+func TestNew_Disabled(t *testing.T) {
+	tlsConfig, err := tlsconfig.New(&config.TLS{CertFile: "missing.pem"})
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
+
+// TestNew verifies the certificate, minimum version and client authentication
+// are applied.
+func TestNew(t *testing.T) {
+	certFile, keyFile, _ := testutils.SelfSignedCert(t)
+
+	tlsConfig, err := tlsconfig.New(&config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"})
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, []string{"h2", "http/1.1"}, tlsConfig.NextProtos)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+
+	tlsConfig, err = tlsconfig.New(&config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, MinVersion: "1.2"})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+}
+
+// TestNew_InvalidFiles verifies missing or invalid files are reported.
+func TestNew_InvalidFiles(t *testing.T) {
+	certFile, keyFile, _ := testutils.SelfSignedCert(t)
+
+	_, err := tlsconfig.New(&config.TLS{Enabled: true, CertFile: filepath.Join(t.TempDir(), "missing.pem"), KeyFile: keyFile})
+	assert.ErrorContains(t, err, "load TLS certificate")
+
+	_, err = tlsconfig.New(&config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile})
+	assert.ErrorContains(t, err, "holds no PEM certificate")
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/buildinfo"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+type Opts struct {
+	Config *config.Tracing
+	Logger logger.Logger
+}
+
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	closer   io.Closer
+}
+
+// NewProvider configures W3C trace context propagation and, when tracing is enabled,
+// installs a global tracer provider exporting spans to the configured exporter.
+// Propagation is always enabled so trace ids from upstream callers are kept in logs
+// even if this service doesn't export spans.
+func NewProvider(ctx context.Context, opts *Opts) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	cfg := opts.Config
+	if !cfg.Enabled {
+		return &Provider{}, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case "otlp":
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, clientOpts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		f, ferr := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if ferr != nil {
+			return nil, fmt.Errorf("failed to open trace file %s: %w", cfg.FilePath, ferr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(buildinfo.Get().Version),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	opts.Logger.Info("Tracing enabled",
+		logger.Field{Key: "exporter", Value: cfg.Exporter},
+		logger.Field{Key: "sample_ratio", Value: cfg.SampleRatio},
+	)
+
+	return &Provider{provider: tp, closer: closer}, nil
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.provider == nil {
+		return nil
+	}
+
+	err := p.provider.Shutdown(ctx)
+	if p.closer != nil {
+		if cerr := p.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tracing"
+)
+
+// TestNewProvider_Disabled verifies propagation is still configured when tracing
+// is disabled and that Shutdown is a no-op.
+func TestNewProvider_Disabled(t *testing.T) {
+	p, err := tracing.NewProvider(context.Background(), &tracing.Opts{
+		Config: &config.Tracing{Enabled: false},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+
+	out := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, out)
+	assert.Contains(t, out["traceparent"], "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+// TestNewProvider_FileExporter verifies spans are written to the configured file.
+func TestNewProvider_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	p, err := tracing.NewProvider(context.Background(), &tracing.Opts{
+		Config: &config.Tracing{
+			Enabled:     true,
+			ServiceName: "test-service",
+			Exporter:    "file",
+			FilePath:    path,
+			SampleRatio: 1,
+		},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "test-span")
+	assert.Contains(t, string(data), "test-service")
+}
+
+// TestNewProvider_InvalidFile verifies an error is returned when the trace file
+// can't be created.
+func TestNewProvider_InvalidFile(t *testing.T) {
+	_, err := tracing.NewProvider(context.Background(), &tracing.Opts{
+		Config: &config.Tracing{
+			Enabled:     true,
+			ServiceName: "test-service",
+			Exporter:    "file",
+			FilePath:    filepath.Join(t.TempDir(), "missing", "traces.json"),
+		},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	assert.Error(t, err)
+}
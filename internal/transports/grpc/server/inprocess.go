@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// InProcessConn is a grpc.ClientConnInterface that calls the registered service
+// handlers directly, without a network hop or serialization, while still running
+// the server's unary interceptor chain. It lets other transports (e.g the HTTP
+// gateway) reuse the gRPC handlers as if they were called over the wire.
+type InProcessConn struct {
+	services    map[string]*inProcessService
+	interceptor grpc.UnaryServerInterceptor
+}
+
+type inProcessService struct {
+	impl    any
+	methods map[string]*grpc.MethodDesc
+}
+
+func newInProcessConn(interceptors ...grpc.UnaryServerInterceptor) *InProcessConn {
+	return &InProcessConn{
+		services:    map[string]*inProcessService{},
+		interceptor: chainUnaryInterceptors(interceptors),
+	}
+}
+
+// RegisterService implements grpc.ServiceRegistrar so the generated RegisterXServer
+// functions can register handlers on the connection.
+func (c *InProcessConn) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	svc := &inProcessService{impl: impl, methods: map[string]*grpc.MethodDesc{}}
+	for i := range desc.Methods {
+		svc.methods[desc.Methods[i].MethodName] = &desc.Methods[i]
+	}
+	c.services[desc.ServiceName] = svc
+}
+
+func (c *InProcessConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "malformed method name %q", method)
+	}
+
+	svc, ok := c.services[serviceName]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown service %s", serviceName)
+	}
+	desc, ok := svc.methods[methodName]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s for service %s", methodName, serviceName)
+	}
+
+	// What the client sends as outgoing metadata is what the handler receives as incoming.
+	md, _ := metadata.FromOutgoingContext(ctx)
+	ctx = metadata.NewIncomingContext(ctx, md.Copy())
+
+	stream := &inProcessStream{method: method}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	dec := func(in any) error {
+		proto.Merge(in.(proto.Message), args.(proto.Message))
+		return nil
+	}
+
+	resp, err := desc.Handler(svc.impl, ctx, dec, c.interceptor)
+
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = stream.headerMD()
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = stream.trailerMD()
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// The network transport fails to marshal a nil response the same way.
+	m, ok := resp.(proto.Message)
+	if !ok || m == nil || !m.ProtoReflect().IsValid() {
+		return status.Errorf(codes.Internal, "%s returned a nil response", method)
+	}
+	proto.Merge(reply.(proto.Message), m)
+	return nil
+}
+
+func (c *InProcessConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Errorf(codes.Unimplemented, "streaming is not supported in-process: %s", method)
+}
+
+// inProcessStream collects the headers and trailers set by handlers through
+// grpc.SetHeader/SendHeader/SetTrailer.
+type inProcessStream struct {
+	mu      sync.Mutex
+	method  string
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *inProcessStream) Method() string {
+	return s.method
+}
+
+func (s *inProcessStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *inProcessStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *inProcessStream) SetTrailer(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func (s *inProcessStream) headerMD() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header.Copy()
+}
+
+func (s *inProcessStream) trailerMD() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.trailer.Copy()
+}
+
+// chainUnaryInterceptors combines interceptors into one, the first one being the
+// outermost, same as grpc.ChainUnaryInterceptor.
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return chainedHandler(interceptors, 0, info, handler)(ctx, req)
+	}
+}
+
+func chainedHandler(interceptors []grpc.UnaryServerInterceptor, i int, info *grpc.UnaryServerInfo, final grpc.UnaryHandler) grpc.UnaryHandler {
+	if i == len(interceptors) {
+		return final
+	}
+	return func(ctx context.Context, req any) (any, error) {
+		return interceptors[i](ctx, req, info, chainedHandler(interceptors, i+1, info, final))
+	}
+}
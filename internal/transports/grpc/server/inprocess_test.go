@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database/model"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+// newSeededServer creates a server backed by an in-memory sqlite database with one user.
+func newSeededServer(t *testing.T, cfg *config.GRPCServer, log logger.Logger) *server.GRPCServer {
+	t.Helper()
+
+	fakeDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := fakeDB.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, fakeDB.AutoMigrate(&model.User{}))
+	require.NoError(t, fakeDB.Create(&model.User{Name: "Alice", Email: "alice@example.com"}).Error)
+
+	mockDB := new(MockDatabaseService)
+	mockDB.On("DB").Return(fakeDB)
+
+	return server.NewServer(&server.Opts{
+		Config:   cfg,
+		Logger:   log,
+		Database: mockDB,
+	})
+}
+
+// TestInProcessConn_Invoke verifies handlers are called through the interceptor
+// chain without a network listener.
+func TestInProcessConn_Invoke(t *testing.T) {
+	var buf bytes.Buffer
+	srv := newSeededServer(t, &config.GRPCServer{}, logger.NewZerologLogger("info", &buf))
+
+	client := helloworld.NewGreeterClient(srv.InProcess)
+
+	resp, err := client.SayHello(context.Background(), &helloworld.SayHelloRequest{UserId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Alice!", resp.Message)
+	assert.Equal(t, "alice@example.com", resp.User.Email)
+
+	// The logger interceptor ran for the in-process call.
+	assert.Contains(t, buf.String(), "/hello_world.Greeter/SayHello")
+
+	_, err = client.SayHello(context.Background(), &helloworld.SayHelloRequest{UserId: 99})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// TestInProcessConn_UnknownMethod verifies unregistered services and methods
+// return codes.Unimplemented, like a network server would.
+func TestInProcessConn_UnknownMethod(t *testing.T) {
+	srv := newSeededServer(t, &config.GRPCServer{}, logger.NewZerologLogger("info", io.Discard))
+
+	var reply helloworld.SayHelloResponse
+	err := srv.InProcess.Invoke(context.Background(), "/unknown.Service/Method", &helloworld.SayHelloRequest{}, &reply)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	err = srv.InProcess.Invoke(context.Background(), "/hello_world.Greeter/Unknown", &helloworld.SayHelloRequest{}, &reply)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = srv.InProcess.NewStream(context.Background(), &grpc.StreamDesc{}, "/hello_world.Greeter/SayHello")
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+type nilGreeter struct {
+	helloworld.UnimplementedGreeterServer
+}
+
+func (nilGreeter) SayHello(context.Context, *helloworld.SayHelloRequest) (*helloworld.SayHelloResponse, error) {
+	return nil, nil
+}
+
+// TestInProcessConn_NilResponse verifies a handler returning neither a response
+// nor an error fails with codes.Internal instead of panicking.
+func TestInProcessConn_NilResponse(t *testing.T) {
+	srv := newSeededServer(t, &config.GRPCServer{}, logger.NewZerologLogger("info", io.Discard))
+	helloworld.RegisterGreeterServer(srv.InProcess, nilGreeter{})
+
+	var reply helloworld.SayHelloResponse
+	err := srv.InProcess.Invoke(context.Background(), "/hello_world.Greeter/SayHello", &helloworld.SayHelloRequest{UserId: 1}, &reply)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
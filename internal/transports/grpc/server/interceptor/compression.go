@@ -0,0 +1,54 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/compression"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+)
+
+type encodingKey struct{}
+
+// withEncoding lets CompressionInterceptor report the response encoding to an
+// interceptor running before it.
+func withEncoding(ctx context.Context) (context.Context, *string) {
+	enc := new(string)
+	return context.WithValue(ctx, encodingKey{}, enc), enc
+}
+
+// CompressionInterceptor compresses responses of at least cfg.MinSize bytes with
+// the preferred compressor the client advertises, smaller ones aren't compressed
+// even when the request was.
+func CompressionInterceptor(cfg *config.Compression) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		name := encoding.Identity
+		if msg, ok := resp.(proto.Message); ok && proto.Size(msg) >= cfg.MinSize {
+			accepted, _ := grpc.ClientSupportedCompressors(ctx)
+			if preferred := compression.Negotiate(accepted, cfg.Algorithms); preferred != "" {
+				name = preferred
+			}
+		}
+
+		// Fails for in-process calls, there's no transport to compress on.
+		if grpc.SetSendCompressor(ctx, name) == nil {
+			if enc, ok := ctx.Value(encodingKey{}).(*string); ok {
+				*enc = name
+			}
+		}
+
+		return resp, nil
+	}
+}
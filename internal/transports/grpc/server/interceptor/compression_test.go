@@ -0,0 +1,99 @@
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/interceptor"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+// largeGreeter answers with a message of the requested size.
+type largeGreeter struct {
+	helloworld.UnimplementedGreeterServer
+}
+
+func (largeGreeter) SayHello(ctx context.Context, req *helloworld.SayHelloRequest) (*helloworld.SayHelloResponse, error) {
+	return &helloworld.SayHelloResponse{Message: strings.Repeat("a", int(req.UserId))}, nil
+}
+
+// TestCompressionInterceptor verifies responses over the minimum size are
+// compressed with the preferred algorithm and the encoding is logged.
+func TestCompressionInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int64
+		encoding string
+	}{
+		{name: "small response", size: 10, encoding: "identity"},
+		{name: "large response", size: 4096, encoding: "zstd"},
+	}
+
+	var buf bytes.Buffer
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.LoggerInterceptor(logger.NewZerologLogger("info", &buf)),
+		interceptor.CompressionInterceptor(&config.Compression{Enabled: true, MinSize: 1024, Algorithms: []string{"zstd", "gzip"}}),
+	))
+	helloworld.RegisterGreeterServer(srv, largeGreeter{})
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			resp, err := helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: tt.size})
+			require.NoError(t, err)
+			assert.Len(t, resp.Message, int(tt.size))
+
+			entry := parseLog(t, &buf)
+			assert.Equal(t, tt.encoding, entry["encoding"])
+		})
+	}
+}
+
+// TestCompressionInterceptor_InProcess verifies calls without a transport are
+// passed through and no encoding is logged.
+func TestCompressionInterceptor_InProcess(t *testing.T) {
+	var buf bytes.Buffer
+	logging := interceptor.LoggerInterceptor(logger.NewZerologLogger("info", &buf))
+	compress := interceptor.CompressionInterceptor(&config.Compression{Enabled: true, Algorithms: []string{"gzip"}})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	resp, err := logging(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return compress(ctx, req, info, func(context.Context, interface{}) (interface{}, error) {
+			return &helloworld.SayHelloResponse{Message: "ok"}, nil
+		})
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.NotContains(t, parseLog(t, &buf), "encoding")
+}
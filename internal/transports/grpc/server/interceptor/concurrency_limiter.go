@@ -0,0 +1,188 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	healthServicePrefix = "/grpc.health.v1.Health/"
+
+	// Number of samples the long term (baseline) latency average is smoothed over.
+	longWindow = 600
+	// Number of samples the short term latency average is smoothed over.
+	shortWindow = 10
+	// Multiplier applied to the limit when a request was dropped (e.g deadline exceeded).
+	backoffRatio = 0.9
+)
+
+// AdaptiveLimiter is a gradient based concurrency limiter. It compares a short term
+// latency average against a long term baseline: when latency grows past the baseline
+// (times the tolerance) the limit shrinks, while latency is stable it grows by a
+// queue allowance of sqrt(limit).
+type AdaptiveLimiter struct {
+	mu        sync.Mutex
+	limit     float64
+	inflight  int
+	minLimit  float64
+	maxLimit  float64
+	smoothing float64
+	tolerance float64
+	longRTT   float64
+	shortRTT  float64
+}
+
+type AdaptiveLimiterOpts struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	Smoothing    float64
+	Tolerance    float64
+}
+
+func NewAdaptiveLimiter(opts *AdaptiveLimiterOpts) *AdaptiveLimiter {
+	return &AdaptiveLimiter{
+		limit:     float64(opts.InitialLimit),
+		minLimit:  float64(opts.MinLimit),
+		maxLimit:  float64(opts.MaxLimit),
+		smoothing: opts.Smoothing,
+		tolerance: opts.Tolerance,
+	}
+}
+
+// Update applies new bounds and tuning, the current limit is clamped to the new
+// bounds. InitialLimit is ignored.
+func (l *AdaptiveLimiter) Update(opts *AdaptiveLimiterOpts) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.minLimit = float64(opts.MinLimit)
+	l.maxLimit = float64(opts.MaxLimit)
+	l.smoothing = opts.Smoothing
+	l.tolerance = opts.Tolerance
+	l.setLimit(l.limit)
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Inflight returns the number of requests currently holding a slot.
+func (l *AdaptiveLimiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+// Acquire reserves a slot for a request, it returns false if the limit is reached
+// and the request should be shed. Every successful Acquire must be paired with Release.
+func (l *AdaptiveLimiter) Acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inflight >= int(l.limit) {
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+// Release frees a slot and feeds the observed latency back into the limit.
+// dropped should be true when the request failed because of overload (e.g timeouts).
+func (l *AdaptiveLimiter) Release(rtt time.Duration, dropped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	inflight := l.inflight
+	l.inflight--
+
+	if dropped {
+		l.setLimit(l.limit * backoffRatio)
+		return
+	}
+
+	sample := float64(rtt)
+	if sample <= 0 {
+		return
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = sample
+		l.shortRTT = sample
+		return
+	}
+	l.longRTT += (sample - l.longRTT) / longWindow
+	l.shortRTT += (sample - l.shortRTT) / shortWindow
+
+	// Latency dropped well below the baseline (e.g after a slow period), let the
+	// baseline catch up faster so the limit can recover.
+	if l.longRTT/l.shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// Don't grow the limit while the server isn't using at least half of it,
+	// there is no signal about how it behaves closer to the limit.
+	if float64(inflight) < l.limit/2 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, l.tolerance*l.longRTT/l.shortRTT))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.setLimit(l.limit*(1-l.smoothing) + newLimit*l.smoothing)
+}
+
+func (l *AdaptiveLimiter) setLimit(limit float64) {
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, limit))
+}
+
+// ConcurrencyLimiterInterceptor sheds requests with codes.Unavailable once the limiter
+// is saturated. Health checks always bypass the limiter so an overloaded instance
+// isn't reported as dead.
+func ConcurrencyLimiterInterceptor(limiter *AdaptiveLimiter) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if !limiter.Acquire() {
+			return nil, status.Errorf(codes.Unavailable, "server is overloaded, try again later")
+		}
+
+		// Deferred so a panicking handler frees its slot, the panic counts as a drop.
+		start := time.Now()
+		panicked := true
+		defer func() {
+			limiter.Release(time.Since(start), panicked || isDropped(err))
+		}()
+
+		resp, err = handler(ctx, req)
+		panicked = false
+		return resp, err
+	}
+}
+
+func isDropped(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return status.Code(err) == codes.DeadlineExceeded
+}
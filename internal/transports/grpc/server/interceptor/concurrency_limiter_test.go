@@ -0,0 +1,197 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/interceptor"
+)
+
+func newTestLimiter(initial int) *interceptor.AdaptiveLimiter {
+	return interceptor.NewAdaptiveLimiter(&interceptor.AdaptiveLimiterOpts{
+		InitialLimit: initial,
+		MinLimit:     1,
+		MaxLimit:     100,
+		Smoothing:    0.5,
+		Tolerance:    1.5,
+	})
+}
+
+// TestAdaptiveLimiter_ShedsAboveLimit verifies that Acquire refuses new slots
+// once the in-flight count reaches the limit and accepts again after a release.
+func TestAdaptiveLimiter_ShedsAboveLimit(t *testing.T) {
+	l := newTestLimiter(2)
+
+	assert.True(t, l.Acquire())
+	assert.True(t, l.Acquire())
+	assert.False(t, l.Acquire(), "expected third request to be shed")
+	assert.Equal(t, 2, l.Inflight())
+
+	l.Release(10*time.Millisecond, false)
+	assert.True(t, l.Acquire())
+}
+
+// TestAdaptiveLimiter_Update verifies new bounds apply to the current limit.
+func TestAdaptiveLimiter_Update(t *testing.T) {
+	l := newTestLimiter(50)
+
+	l.Update(&interceptor.AdaptiveLimiterOpts{MinLimit: 1, MaxLimit: 10, Smoothing: 0.5, Tolerance: 1.5})
+	assert.Equal(t, 10, l.Limit())
+
+	l.Update(&interceptor.AdaptiveLimiterOpts{MinLimit: 20, MaxLimit: 100, Smoothing: 0.5, Tolerance: 1.5})
+	assert.Equal(t, 20, l.Limit())
+}
+
+// TestAdaptiveLimiter_ShrinksOnLatencyIncrease verifies the limit goes down when
+// latency grows well past the baseline.
+func TestAdaptiveLimiter_ShrinksOnLatencyIncrease(t *testing.T) {
+	l := newTestLimiter(20)
+
+	// Establish a baseline while saturated.
+	for i := 0; i < 50; i++ {
+		fillAndRelease(l, 10*time.Millisecond)
+	}
+	before := l.Limit()
+
+	for i := 0; i < 50; i++ {
+		fillAndRelease(l, 500*time.Millisecond)
+	}
+
+	assert.Less(t, l.Limit(), before)
+}
+
+// TestAdaptiveLimiter_GrowsWhenLatencyStable verifies the limit increases while
+// the server is saturated but latency stays at the baseline.
+func TestAdaptiveLimiter_GrowsWhenLatencyStable(t *testing.T) {
+	l := newTestLimiter(10)
+
+	for i := 0; i < 20; i++ {
+		fillAndRelease(l, 10*time.Millisecond)
+	}
+
+	assert.Greater(t, l.Limit(), 10)
+}
+
+// TestAdaptiveLimiter_BacksOffOnDrop verifies dropped requests reduce the limit.
+func TestAdaptiveLimiter_BacksOffOnDrop(t *testing.T) {
+	l := newTestLimiter(50)
+
+	require.True(t, l.Acquire())
+	l.Release(time.Second, true)
+
+	assert.Less(t, l.Limit(), 50)
+}
+
+// TestConcurrencyLimiterInterceptor_Sheds verifies that requests beyond the limit
+// are rejected with codes.Unavailable without reaching the handler.
+func TestConcurrencyLimiterInterceptor_Sheds(t *testing.T) {
+	l := newTestLimiter(1)
+	interceptorFn := interceptor.ConcurrencyLimiterInterceptor(l)
+
+	require.True(t, l.Acquire()) // occupy the only slot
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	resp, err := interceptorFn(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+		handler,
+	)
+
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.False(t, called)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// TestConcurrencyLimiterInterceptor_HealthBypass verifies health checks are
+// served even when the limiter is saturated.
+func TestConcurrencyLimiterInterceptor_HealthBypass(t *testing.T) {
+	l := newTestLimiter(1)
+	interceptorFn := interceptor.ConcurrencyLimiterInterceptor(l)
+
+	require.True(t, l.Acquire())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := interceptorFn(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		handler,
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+// TestConcurrencyLimiterInterceptor_ReleasesSlot verifies that the slot is freed
+// once the handler returns.
+func TestConcurrencyLimiterInterceptor_ReleasesSlot(t *testing.T) {
+	l := newTestLimiter(1)
+	interceptorFn := interceptor.ConcurrencyLimiterInterceptor(l)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := interceptorFn(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+			handler,
+		)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, l.Inflight())
+}
+
+// TestConcurrencyLimiterInterceptor_ReleasesSlotOnPanic verifies that a panicking
+// handler frees its slot and backs the limit off like a dropped request.
+func TestConcurrencyLimiterInterceptor_ReleasesSlotOnPanic(t *testing.T) {
+	l := newTestLimiter(10)
+	interceptorFn := interceptor.ConcurrencyLimiterInterceptor(l)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_, _ = interceptorFn(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+			handler,
+		)
+	})
+
+	assert.Equal(t, 0, l.Inflight())
+	assert.Equal(t, 9, l.Limit())
+}
+
+// fillAndRelease saturates the limiter and releases every slot with the given latency.
+func fillAndRelease(l *interceptor.AdaptiveLimiter, rtt time.Duration) {
+	acquired := 0
+	for l.Acquire() {
+		acquired++
+	}
+	for i := 0; i < acquired; i++ {
+		l.Release(rtt, false)
+	}
+}
@@ -17,16 +17,22 @@ func LoggerInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (resp interface{}, err error) {
 		start := time.Now()
+		log := log.WithContext(ctx)
 
+		ctx, encoding := withEncoding(ctx)
 		resp, err = handler(ctx, req)
 
 		elapsedMs := fmt.Sprintf("%.2fms", time.Since(start).Seconds()*1000)
 
 		if err == nil {
-			log.Info("gRPC request completed",
-				logger.Field{Key: "method", Value: info.FullMethod},
-				logger.Field{Key: "duration", Value: elapsedMs},
-			)
+			fields := []logger.Field{
+				{Key: "method", Value: info.FullMethod},
+				{Key: "duration", Value: elapsedMs},
+			}
+			if *encoding != "" {
+				fields = append(fields, logger.Field{Key: "encoding", Value: *encoding})
+			}
+			log.Info("gRPC request completed", fields...)
 		} else {
 			log.Error("gRPC request failed",
 				logger.Field{Key: "method", Value: info.FullMethod},
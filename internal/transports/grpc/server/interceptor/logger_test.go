@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
@@ -93,3 +94,30 @@ func TestLoggingInterceptor_Error(t *testing.T) {
 	assert.Contains(t, entry["duration"], "ms")
 	assert.Equal(t, "boom", entry["error"])
 }
+
+// TestLoggingInterceptor_TraceContext verifies the trace and span ids of the
+// request span are added to the log entry.
+func TestLoggingInterceptor_TraceContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewZerologLogger("info", &buf)
+
+	interceptorFn := interceptor.LoggerInterceptor(log)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err := interceptorFn(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+	require.NoError(t, err)
+
+	entry := parseLog(t, &buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", entry["span_id"])
+}
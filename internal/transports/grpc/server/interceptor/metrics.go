@@ -0,0 +1,29 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func MetricsInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		start := time.Now()
+
+		resp, err = handler(ctx, req)
+
+		code := status.Code(err).String()
+		m.GRPCRequestsTotal.WithLabelValues(info.FullMethod, code).Inc()
+		m.GRPCRequestDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	}
+}
@@ -0,0 +1,43 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/interceptor"
+)
+
+// TestMetricsInterceptor verifies requests are counted and timed per method and code.
+func TestMetricsInterceptor(t *testing.T) {
+	m, err := metrics.NewMetrics(&metrics.Opts{})
+	require.NoError(t, err)
+
+	interceptorFn := interceptor.MetricsInterceptor(m)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+
+	_, err = interceptorFn(context.Background(), nil, info, ok)
+	require.NoError(t, err)
+	_, err = interceptorFn(context.Background(), nil, info, ok)
+	require.NoError(t, err)
+	_, err = interceptorFn(context.Background(), nil, info, notFound)
+	require.Error(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.GRPCRequestsTotal.WithLabelValues("/test.Service/Method", "OK")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.GRPCRequestsTotal.WithLabelValues("/test.Service/Method", "NotFound")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.GRPCRequestDuration))
+}
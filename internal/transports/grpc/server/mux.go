@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc"
+)
+
+// ShareListener makes Serve share the gRPC listener with an HTTP server, for
+// environments exposing a single port. gRPC requests (HTTP/2 with an application/grpc
+// content type) go to the gRPC server and everything else to srv. It must be called
+// before Serve, srv is then shut down by Shutdown before the gRPC server.
+func (s *GRPCServer) ShareListener(srv *http.Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shared = srv
+}
+
+// serveShared splits the listener between the gRPC server and the shared HTTP
+// server by sniffing the first bytes of every connection.
+func (s *GRPCServer) serveShared(listener net.Listener) error {
+	mux := cmux.New(listener)
+	// gRPC clients wait for the server's SETTINGS frame before sending headers.
+	grpcListener := mux.MatchWithWriters(cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc"))
+	httpListener := mux.Match(cmux.Any())
+
+	s.mu.Lock()
+	s.cmux = mux
+	s.mu.Unlock()
+
+	// The TLS listener hands over decrypted connections, so HTTP/2 clients
+	// negotiating h2 look like h2c to the HTTP server.
+	if s.TLS != nil {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		s.shared.Protocols = protocols
+	}
+
+	s.Logger.Info("gRPC server started",
+		logger.Field{Key: "address", Value: listener.Addr().String()},
+		logger.Field{Key: "protocols", Value: []string{"grpc", "http"}},
+		logger.Field{Key: "tls", Value: s.TLS != nil},
+	)
+
+	errs := make(chan error, 3)
+	go func() { errs <- s.Server.Serve(grpcListener) }()
+	go func() { errs <- s.shared.Serve(httpListener) }()
+	go func() { errs <- mux.Serve() }()
+
+	for range 3 {
+		if err := <-errs; err != nil && !isListenerClosed(err) {
+			s.Logger.Error("gRPC server failed", logger.Field{Key: "error", Value: err.Error()})
+			return err
+		}
+	}
+	return nil
+}
+
+// isListenerClosed reports whether err is only the result of a shutdown, the
+// matched listeners all close the shared one.
+func isListenerClosed(err error) bool {
+	return errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, http.ErrServerClosed) ||
+		errors.Is(err, grpc.ErrServerStopped) ||
+		errors.Is(err, cmux.ErrServerClosed) ||
+		errors.Is(err, cmux.ErrListenerClosed)
+}
@@ -0,0 +1,117 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+// newSharedHTTPServer returns an HTTP server answering every request with "http ok".
+func newSharedHTTPServer() *http.Server {
+	return &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "http ok")
+	})}
+}
+
+// httpGet returns the body of a GET request to addr.
+func httpGet(t *testing.T, addr, path string) string {
+	t.Helper()
+
+	resp, err := http.Get("http://" + addr + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// TestServeListener_SharedHTTP verifies gRPC and HTTP are served on one listener
+// and both are shut down by Shutdown.
+func TestServeListener_SharedHTTP(t *testing.T) {
+	srv := newSeededServer(t, &config.GRPCServer{MultiplexHTTP: true}, logger.NewZerologLogger("info", io.Discard))
+
+	shared := newSharedHTTPServer()
+	httpDown := make(chan struct{})
+	shared.RegisterOnShutdown(func() { close(httpDown) })
+	srv.ShareListener(shared)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ServeListener(lis)
+	}()
+
+	addr := lis.Addr().String()
+	assert.Equal(t, "http ok", httpGet(t, addr, "/healthz"))
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Alice!", resp.Message)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeListener did not return after Shutdown")
+	}
+	select {
+	case <-httpDown:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shared HTTP server was not shut down")
+	}
+
+	_, err = net.DialTimeout("tcp", addr, time.Second)
+	assert.Error(t, err, "listener must be closed after shutdown")
+}
+
+// TestServeListener_SharedWeb verifies RPC protocols and plain HTTP requests are
+// routed apart when gRPC-Web is enabled on a shared listener.
+func TestServeListener_SharedWeb(t *testing.T) {
+	srv := newSeededServer(t, &config.GRPCServer{WebEnabled: true, MultiplexHTTP: true}, logger.NewZerologLogger("info", io.Discard))
+	srv.ShareListener(newSharedHTTPServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	t.Cleanup(func() {
+		_ = srv.Shutdown(context.Background())
+	})
+
+	addr := lis.Addr().String()
+	assert.Equal(t, "http ok", httpGet(t, addr, "/v1/users/1/hello"))
+
+	resp, err := postConnect(addr, "application/json", strings.NewReader(`{"user_id": 1}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "Hello, Alice!")
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// serverOptions maps the connection settings to gRPC server options, zero values
+// keep the gRPC defaults.
+func serverOptions(cfg *config.GRPCServer) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     cfg.MaxConnectionIdle,
+			MaxConnectionAge:      cfg.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+			Time:                  cfg.KeepaliveTime,
+			Timeout:               cfg.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}),
+	}
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(cfg.MaxConcurrentStreams)))
+	}
+	if cfg.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(cfg.ConnectionTimeout))
+	}
+
+	return opts
+}
+
+// http2Config applies the settings net/http supports when it serves the
+// connections instead of the gRPC transport, see serveWeb.
+func http2Config(cfg *config.GRPCServer) *http.HTTP2Config {
+	return &http.HTTP2Config{
+		MaxConcurrentStreams: cfg.MaxConcurrentStreams,
+		SendPingTimeout:      cfg.KeepaliveTime,
+		PingTimeout:          cfg.KeepaliveTimeout,
+	}
+}
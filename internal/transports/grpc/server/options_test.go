@@ -0,0 +1,56 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+// TestNewServer_MessageSizeLimits verifies requests and responses over the
+// configured sizes are rejected with ResourceExhausted.
+func TestNewServer_MessageSizeLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.GRPCServer
+	}{
+		{name: "receive", cfg: &config.GRPCServer{MaxRecvMsgSize: 1}},
+		{name: "send", cfg: &config.GRPCServer{MaxSendMsgSize: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSeededServer(t, tt.cfg, logger.NewZerologLogger("info", io.Discard))
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() {
+				_ = srv.ServeListener(lis)
+			}()
+			defer srv.Server.Stop()
+
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// The request encodes to 2 bytes and the response to more than 10.
+			_, err = helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: 1})
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+		})
+	}
+}
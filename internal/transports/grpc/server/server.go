@@ -1,46 +1,135 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"net"
+	"net/http"
+	"sort"
+	"sync"
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/database"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/metrics"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/service"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/handler"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server/interceptor"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/socket"
 	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+	"github.com/soheilhy/cmux"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 type Opts struct {
-	Config   *config.GRPCServer
-	Logger   logger.Logger
-	Database database.DatabaseService
+	Config             *config.GRPCServer
+	ConcurrencyLimiter *config.ConcurrencyLimiter
+	Logger             logger.Logger
+	Database           database.DatabaseService
+	Metrics            *metrics.Metrics    // optional
+	Compression        *config.Compression // optional
+	TLS                *tls.Config         // optional, see tlsconfig.New
 }
 
 type GRPCServer struct {
-	Server   *grpc.Server
-	Config   *config.GRPCServer
-	Logger   logger.Logger
-	Database database.Database
+	Server    *grpc.Server
+	Health    *health.Server
+	Limiter   *interceptor.AdaptiveLimiter // nil when concurrency limiting is disabled
+	InProcess *InProcessConn               // calls the registered handlers directly, used by other transports
+	Config    *config.GRPCServer
+	TLS       *tls.Config // nil serves plaintext
+	Logger    logger.Logger
+	Database  database.Database
+
+	mu     sync.Mutex
+	web    *http.Server // set while serving gRPC-Web and Connect next to gRPC
+	shared *http.Server // HTTP server sharing the listener, see ShareListener
+	cmux   cmux.CMux
 }
 
 func NewServer(opts *Opts) *GRPCServer {
-	srv := grpc.NewServer(grpc.UnaryInterceptor(interceptor.LoggerInterceptor(opts.Logger)))
-	helloworld.RegisterGreeterServer(srv, handler.NewGreeterServer(
-		service.NewUserService(opts.Database),
-	))
+	interceptors := []grpc.UnaryServerInterceptor{
+		interceptor.LoggerInterceptor(opts.Logger),
+	}
+	if opts.Metrics != nil {
+		interceptors = append(interceptors, interceptor.MetricsInterceptor(opts.Metrics))
+	}
+
+	var limiter *interceptor.AdaptiveLimiter
+	if cfg := opts.ConcurrencyLimiter; cfg != nil && cfg.Enabled {
+		limiter = interceptor.NewAdaptiveLimiter(&interceptor.AdaptiveLimiterOpts{
+			InitialLimit: cfg.InitialLimit,
+			MinLimit:     cfg.MinLimit,
+			MaxLimit:     cfg.MaxLimit,
+			Smoothing:    cfg.Smoothing,
+			Tolerance:    cfg.Tolerance,
+		})
+		interceptors = append(interceptors, interceptor.ConcurrencyLimiterInterceptor(limiter))
+	}
+	if cfg := opts.Compression; cfg != nil && cfg.Enabled {
+		interceptors = append(interceptors, interceptor.CompressionInterceptor(cfg))
+	}
+
+	srv := grpc.NewServer(append(serverOptions(opts.Config),
+		// Extracts the W3C traceparent from incoming metadata and starts a span per RPC,
+		// it runs before the interceptors so they all see the span in the context.
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
+	)...)
+
+	healthServer := health.NewServer()
+
+	// Application services are registered on both the network server and the
+	// in-process connection, so other transports can reuse them.
+	inProcess := newInProcessConn(interceptors...)
+	greeter := handler.NewGreeterServer(service.NewUserService(opts.Database))
+	for _, r := range []grpc.ServiceRegistrar{srv, inProcess} {
+		healthpb.RegisterHealthServer(r, healthServer)
+		helloworld.RegisterGreeterServer(r, greeter)
+	}
+
+	if opts.Config.ReflectionEnabled {
+		reflection.Register(srv)
+	}
+	if opts.Config.ChannelzEnabled {
+		channelzservice.RegisterChannelzServiceToServer(srv)
+	}
 
 	return &GRPCServer{
-		Server: srv,
-		Config: opts.Config,
-		Logger: opts.Logger,
+		Server:    srv,
+		Health:    healthServer,
+		Limiter:   limiter,
+		InProcess: inProcess,
+		Config:    opts.Config,
+		TLS:       opts.TLS,
+		Logger:    opts.Logger,
 	}
 }
 
+// ServeListener serves on listener until Shutdown. With TLS, the connections are
+// decrypted before being routed, so every protocol on the port is secured.
 func (s *GRPCServer) ServeListener(listener net.Listener) error {
-	s.Logger.Info("gRPC server started", logger.Field{Key: "address", Value: listener.Addr().String()})
+	s.Logger.Info("gRPC services registered", logger.Field{Key: "services", Value: s.Services()})
+	if s.TLS != nil {
+		listener = tls.NewListener(listener, s.TLS)
+	}
+	switch {
+	case s.Config.WebEnabled:
+		return s.serveWeb(listener)
+	case s.shared != nil:
+		return s.serveShared(listener)
+	}
+
+	s.Logger.Info("gRPC server started",
+		logger.Field{Key: "address", Value: listener.Addr().String()},
+		logger.Field{Key: "tls", Value: s.TLS != nil},
+	)
 	if err := s.Server.Serve(listener); err != nil {
 		s.Logger.Error("gRPC server failed", logger.Field{Key: "error", Value: err.Error()})
 		return err
@@ -48,11 +137,127 @@ func (s *GRPCServer) ServeListener(listener net.Listener) error {
 	return nil
 }
 
+// serveWeb serves gRPC, gRPC-Web and Connect on the same listener, requests are
+// routed on their content type. HTTP/2 is accepted without TLS (h2c) for gRPC clients.
+func (s *GRPCServer) serveWeb(listener net.Listener) error {
+	var fallback http.Handler
+	if s.shared != nil {
+		fallback = s.shared.Handler
+	}
+
+	handler, err := newWebHandler(s.Server, s.Config.CORSAllowedOrigins, fallback)
+	if err != nil {
+		s.Logger.Error("gRPC server failed", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	web := &http.Server{
+		Handler:           handler,
+		Protocols:         &protocols,
+		HTTP2:             http2Config(s.Config),
+		ReadHeaderTimeout: s.Config.ConnectionTimeout,
+		IdleTimeout:       s.Config.MaxConnectionIdle,
+	}
+	s.mu.Lock()
+	s.web = web
+	s.mu.Unlock()
+
+	served := []string{"grpc", "grpc-web", "connect"}
+	if fallback != nil {
+		served = append(served, "http")
+	}
+	s.Logger.Info("gRPC server started",
+		logger.Field{Key: "address", Value: listener.Addr().String()},
+		logger.Field{Key: "protocols", Value: served},
+		logger.Field{Key: "tls", Value: s.TLS != nil},
+	)
+	if err := web.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.Logger.Error("gRPC server failed", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new connections and waits for in-flight RPCs on every
+// protocol to finish. Remaining RPCs are cancelled once ctx is done.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	web, shared, mux := s.web, s.shared, s.cmux
+	s.mu.Unlock()
+
+	var errs []error
+
+	// The HTTP transport calls the gRPC handlers in-process, so it's drained first.
+	if shared != nil {
+		errs = append(errs, shutdownHTTP(ctx, shared))
+	}
+
+	if web != nil {
+		// The gRPC server doesn't own these connections, so it can't drain them,
+		// Stop only cancels whatever is left after the HTTP server shut down.
+		errs = append(errs, shutdownHTTP(ctx, web))
+		s.Server.Stop()
+	} else {
+		errs = append(errs, s.gracefulStop(ctx))
+	}
+
+	if mux != nil {
+		mux.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
+// shutdownHTTP drains srv, the connections still open when ctx is done are closed.
+func shutdownHTTP(ctx context.Context, srv *http.Server) error {
+	err := srv.Shutdown(ctx)
+	if ctx.Err() != nil {
+		_ = srv.Close()
+	}
+	return err
+}
+
+// gracefulStop waits for in-flight RPCs to finish, the ones left when ctx is done are cancelled.
+func (s *GRPCServer) gracefulStop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		return ctx.Err()
+	}
+}
+
+// Services returns the fully qualified name of every registered service with its methods.
+func (s *GRPCServer) Services() map[string][]string {
+	services := map[string][]string{}
+	for name, info := range s.Server.GetServiceInfo() {
+		methods := make([]string, 0, len(info.Methods))
+		for _, m := range info.Methods {
+			methods = append(methods, m.Name)
+		}
+		sort.Strings(methods)
+		services[name] = methods
+	}
+	return services
+}
+
 func (s *GRPCServer) Serve() error {
 	url := s.Config.URL
-	listener, err := net.Listen("tcp", url)
+	listener, err := socket.Listen(url, &socket.Opts{SocketMode: s.Config.SocketMode})
 	if err != nil {
-		s.Logger.Error("Failed to create tcp listener on %q: %v",
+		s.Logger.Error("Failed to create listener",
 			logger.Field{Key: "address", Value: url},
 			logger.Field{Key: "error", Value: err.Error()},
 		)
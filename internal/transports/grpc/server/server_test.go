@@ -2,15 +2,32 @@ package server_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tests/testutils"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tlsconfig"
 	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -103,3 +120,248 @@ func TestServe(t *testing.T) {
 
 	assert.Contains(t, buf.String(), "gRPC server started")
 }
+
+// TestServe_UnixSocket verifies the server listens on a unix socket for unix:// URLs.
+func TestServe_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grpc.sock")
+	srv := newSeededServer(t, &config.GRPCServer{URL: "unix://" + path, SocketMode: 0600}, logger.NewZerologLogger("info", io.Discard))
+
+	go func() {
+		_ = srv.Serve()
+	}()
+	defer srv.Server.Stop()
+
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+// TestNewServer_ConcurrencyLimiter verifies the limiter is only created when enabled
+// and that health checks are served through it.
+func TestNewServer_ConcurrencyLimiter(t *testing.T) {
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	fakeDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockDB := new(MockDatabaseService)
+	mockDB.On("DB").Return(fakeDB)
+
+	srv := server.NewServer(&server.Opts{
+		Config:   &config.GRPCServer{},
+		Logger:   log,
+		Database: mockDB,
+	})
+	assert.Nil(t, srv.Limiter)
+
+	srv = server.NewServer(&server.Opts{
+		Config: &config.GRPCServer{},
+		ConcurrencyLimiter: &config.ConcurrencyLimiter{
+			Enabled:      true,
+			InitialLimit: 1,
+			MinLimit:     1,
+			MaxLimit:     10,
+			Smoothing:    0.2,
+			Tolerance:    1.5,
+		},
+		Logger:   log,
+		Database: mockDB,
+	})
+	require.NotNil(t, srv.Limiter)
+	assert.Equal(t, 1, srv.Limiter.Limit())
+
+	lis := bufconn.Listen(bufSize)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	defer srv.Server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Occupy the only slot, health checks must still pass.
+	require.True(t, srv.Limiter.Acquire())
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+// TestServer_TraceContextPropagation verifies the W3C traceparent sent by a caller
+// is picked up by the server and shows up in the request log.
+func TestServer_TraceContextPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var buf bytes.Buffer
+	log := logger.NewZerologLogger("info", &buf)
+
+	fakeDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockDB := new(MockDatabaseService)
+	mockDB.On("DB").Return(fakeDB)
+
+	srv := server.NewServer(&server.Opts{
+		Config:   &config.GRPCServer{},
+		Logger:   log,
+		Database: mockDB,
+	})
+
+	lis := bufconn.Listen(bufSize)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	defer srv.Server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	)
+	_, _ = helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: 1})
+
+	assert.Contains(t, buf.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+}
+
+// TestNewServer_ReflectionAndChannelz verifies reflection and channelz are only
+// registered when enabled and that registered services are logged on startup.
+func TestNewServer_ReflectionAndChannelz(t *testing.T) {
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	fakeDB, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+
+	mockDB := new(MockDatabaseService)
+	mockDB.On("DB").Return(fakeDB)
+
+	srv := server.NewServer(&server.Opts{
+		Config:   &config.GRPCServer{},
+		Logger:   log,
+		Database: mockDB,
+	})
+	services := srv.Services()
+	assert.Contains(t, services, "hello_world.Greeter")
+	assert.Equal(t, []string{"SayHello"}, services["hello_world.Greeter"])
+	assert.NotContains(t, services, "grpc.reflection.v1.ServerReflection")
+	assert.NotContains(t, services, "grpc.channelz.v1.Channelz")
+
+	var buf syncBuffer
+	srv = server.NewServer(&server.Opts{
+		Config:   &config.GRPCServer{ReflectionEnabled: true, ChannelzEnabled: true},
+		Logger:   logger.NewZerologLogger("info", &buf),
+		Database: mockDB,
+	})
+	services = srv.Services()
+	assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+	assert.Contains(t, services, "grpc.channelz.v1.Channelz")
+
+	lis := bufconn.Listen(bufSize)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	defer srv.Server.Stop()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "gRPC services registered")
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, buf.String(), `"hello_world.Greeter":["SayHello"]`)
+}
+
+// syncBuffer is a bytes.Buffer safe to read while a server goroutine logs to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestServeListener_TLS verifies gRPC and the HTTP requests sharing the port are
+// served over TLS in every mode.
+func TestServeListener_TLS(t *testing.T) {
+	certFile, keyFile, pool := testutils.SelfSignedCert(t)
+	tlsConfig, err := tlsconfig.New(&config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2"})
+	require.NoError(t, err)
+
+	tests := map[string]*config.GRPCServer{
+		"grpc":   {},
+		"shared": {MultiplexHTTP: true},
+		"web":    {WebEnabled: true, MultiplexHTTP: true},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newSeededServer(t, cfg, logger.NewZerologLogger("info", io.Discard))
+			srv.TLS = tlsConfig
+			if cfg.MultiplexHTTP {
+				srv.ShareListener(newSharedHTTPServer())
+			}
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() {
+				_ = srv.ServeListener(lis)
+			}()
+			defer srv.Shutdown(context.Background())
+
+			addr := lis.Addr().String()
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			resp, err := helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: 1})
+			require.NoError(t, err)
+			assert.Equal(t, "Hello, Alice!", resp.Message)
+
+			plain, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer plain.Close()
+			_, err = healthpb.NewHealthClient(plain).Check(ctx, &healthpb.HealthCheckRequest{})
+			assert.Error(t, err, "plaintext clients must be refused")
+
+			if !cfg.MultiplexHTTP {
+				return
+			}
+			for _, h2 := range []bool{false, true} {
+				client := &http.Client{Transport: &http.Transport{
+					TLSClientConfig:   &tls.Config{RootCAs: pool},
+					ForceAttemptHTTP2: h2,
+				}}
+				res, err := client.Get("https://" + addr + "/healthz")
+				require.NoError(t, err)
+				body, _ := io.ReadAll(res.Body)
+				res.Body.Close()
+				assert.Equal(t, "http ok", string(body))
+				assert.Equal(t, h2, res.ProtoMajor == 2)
+			}
+		})
+	}
+}
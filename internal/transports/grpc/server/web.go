@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	connectcors "connectrpc.com/cors"
+	"connectrpc.com/vanguard/vanguardgrpc"
+	"github.com/rs/cors"
+	"google.golang.org/grpc"
+)
+
+// newWebHandler returns a handler serving gRPC, gRPC-Web and the Connect protocol
+// (JSON and binary) for every service registered on srv. Native gRPC requests are
+// passed straight to the server, the other protocols are transcoded to gRPC first,
+// so the interceptor chain runs for all of them. Other requests go to fallback
+// when it's set, e.g the HTTP transport sharing the listener.
+func newWebHandler(srv *grpc.Server, allowedOrigins []string, fallback http.Handler) (http.Handler, error) {
+	transcoder, err := vanguardgrpc.NewTranscoder(srv)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case isGRPCRequest(r):
+			srv.ServeHTTP(w, r)
+		case fallback == nil || isRPCRequest(r):
+			transcoder.ServeHTTP(w, r)
+		default:
+			fallback.ServeHTTP(w, r)
+		}
+	})
+
+	// rs/cors allows every origin when the list is empty, keep browsers to
+	// same-origin calls instead until origins are configured.
+	if len(allowedOrigins) == 0 {
+		return mux, nil
+	}
+
+	return cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: connectcors.AllowedMethods(),
+		AllowedHeaders: connectcors.AllowedHeaders(),
+		ExposedHeaders: connectcors.ExposedHeaders(),
+	}).Handler(mux), nil
+}
+
+// isGRPCRequest reports whether r uses the native gRPC protocol, gRPC-Web uses
+// "application/grpc-web" and Connect uses "application/proto", "application/json"
+// or "application/connect+...".
+func isGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 &&
+		strings.HasPrefix(contentType, "application/grpc") &&
+		!strings.HasPrefix(contentType, "application/grpc-web")
+}
+
+// isRPCRequest reports whether r uses one of the RPC protocols rather than being
+// a plain HTTP request. Connect unary requests are told apart by their protocol
+// version header, or query parameter for GET requests.
+func isRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/grpc") ||
+		strings.HasPrefix(contentType, "application/connect+") ||
+		r.Header.Get("Connect-Protocol-Version") == "1" ||
+		r.URL.Query().Get("connect") == "v1"
+}
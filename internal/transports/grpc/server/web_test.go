@@ -0,0 +1,184 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/grpc/server"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+const sayHelloPath = "/hello_world.Greeter/SayHello"
+
+// startWebServer serves a seeded server with gRPC-Web and Connect enabled on a
+// random local port and returns its address.
+func startWebServer(t *testing.T, log logger.Logger) (*server.GRPCServer, string) {
+	t.Helper()
+
+	srv := newSeededServer(t, &config.GRPCServer{
+		WebEnabled:         true,
+		CORSAllowedOrigins: []string{"https://app.example.com"},
+	}, log)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	t.Cleanup(func() {
+		_ = srv.Shutdown(context.Background())
+	})
+
+	return srv, lis.Addr().String()
+}
+
+// TestServeListener_WebProtocols verifies gRPC, gRPC-Web and Connect (JSON and
+// binary) are served on the same listener and all go through the interceptors.
+func TestServeListener_WebProtocols(t *testing.T) {
+	var buf bytes.Buffer
+	_, addr := startWebServer(t, logger.NewZerologLogger("info", &buf))
+
+	reqMsg, err := proto.Marshal(&helloworld.SayHelloRequest{UserId: 1})
+	require.NoError(t, err)
+
+	t.Run("grpc", func(t *testing.T) {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := helloworld.NewGreeterClient(conn).SayHello(ctx, &helloworld.SayHelloRequest{UserId: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, Alice!", resp.Message)
+	})
+
+	t.Run("connect json", func(t *testing.T) {
+		resp, err := postConnect(addr, "application/json", strings.NewReader(`{"user_id": 1}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+		assert.Contains(t, string(body), "Hello, Alice!")
+	})
+
+	t.Run("connect proto", func(t *testing.T) {
+		resp, err := postConnect(addr, "application/proto", bytes.NewReader(reqMsg))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var out helloworld.SayHelloResponse
+		require.NoError(t, proto.Unmarshal(body, &out))
+		assert.Equal(t, "Hello, Alice!", out.Message)
+	})
+
+	t.Run("grpc-web", func(t *testing.T) {
+		frame := make([]byte, 5, 5+len(reqMsg))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(reqMsg)))
+		frame = append(frame, reqMsg...)
+
+		resp, err := http.Post("http://"+addr+sayHelloPath, "application/grpc-web+proto", bytes.NewReader(frame))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.GreaterOrEqual(t, len(body), 5)
+
+		// The first frame holds the message, the trailers follow in a second frame.
+		size := binary.BigEndian.Uint32(body[1:5])
+		var out helloworld.SayHelloResponse
+		require.NoError(t, proto.Unmarshal(body[5:5+size], &out))
+		assert.Equal(t, "Hello, Alice!", out.Message)
+		assert.Contains(t, strings.ToLower(string(body[5+size:])), "grpc-status: 0")
+	})
+
+	assert.Equal(t, 4, strings.Count(buf.String(), sayHelloPath), "every protocol must go through the logger interceptor")
+}
+
+// TestServeListener_WebCORS verifies preflight requests are only answered for
+// the configured origins.
+func TestServeListener_WebCORS(t *testing.T) {
+	_, addr := startWebServer(t, logger.NewZerologLogger("info", io.Discard))
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, "http://"+addr+sayHelloPath, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "connect-protocol-version,content-type")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := preflight("https://app.example.com")
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = preflight("https://evil.example.com")
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+// postConnect sends a Connect unary request, the protocol version header tells it
+// apart from a plain REST call.
+func postConnect(addr, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr+sayHelloPath, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Connect-Protocol-Version", "1")
+	return http.DefaultClient.Do(req)
+}
+
+// TestServeListener_WebReadHeaderTimeout verifies connections that don't send
+// their request headers within the connection timeout are closed.
+func TestServeListener_WebReadHeaderTimeout(t *testing.T) {
+	srv := newSeededServer(t, &config.GRPCServer{
+		WebEnabled:        true,
+		ConnectionTimeout: 100 * time.Millisecond,
+	}, logger.NewZerologLogger("info", io.Discard))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	t.Cleanup(func() {
+		_ = srv.Shutdown(context.Background())
+	})
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("POST " + sayHelloPath + " HTTP/1.1\r\nHost: localhost\r\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.ReadAll(conn)
+	assert.NoError(t, err, "the server closes the connection before the deadline")
+}
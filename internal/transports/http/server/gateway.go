@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// gatewayHandlers exposes gRPC services annotated with google.api.http as JSON over HTTP.
+// Add the generated RegisterXHandlerClient of new services here.
+var gatewayHandlers = []struct {
+	Name     string
+	Register func(ctx context.Context, mux *runtime.ServeMux, conn grpc.ClientConnInterface) error
+}{
+	{Name: "hello_world.Greeter", Register: func(ctx context.Context, mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+		return helloworld.RegisterGreeterHandlerClient(ctx, mux, helloworld.NewGreeterClient(conn))
+	}},
+}
+
+func registerGateway(ctx context.Context, mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
+	for _, h := range gatewayHandlers {
+		if err := h.Register(ctx, mux, conn); err != nil {
+			return fmt.Errorf("failed to register %s gateway handler: %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// errorHandler writes gRPC errors as JSON with the HTTP status mapped from the gRPC
+// code (e.g NotFound -> 404, Unavailable -> 503), server side failures are logged.
+func errorHandler(log logger.Logger) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		st := status.Convert(err)
+		if code := runtime.HTTPStatusFromCode(st.Code()); code >= http.StatusInternalServerError {
+			log.WithContext(ctx).Error("HTTP request failed",
+				logger.Field{Key: "path", Value: r.URL.Path},
+				logger.Field{Key: "status", Value: code},
+				logger.Field{Key: "error", Value: st.Message()},
+			)
+		}
+
+		runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, err)
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthHandler reports the gRPC health status for probes and load balancers that
+// can't speak gRPC: 200 while SERVING and 503 otherwise. The ?service= query
+// parameter checks a single service instead of the whole server.
+func healthHandler(conn grpc.ClientConnInterface) http.HandlerFunc {
+	client := healthpb.NewHealthClient(conn)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, code := healthpb.HealthCheckResponse_UNKNOWN.String(), http.StatusServiceUnavailable
+
+		resp, err := client.Check(r.Context(), &healthpb.HealthCheckRequest{Service: r.URL.Query().Get("service")})
+		if err == nil {
+			status = resp.Status.String()
+			if resp.Status == healthpb.HealthCheckResponse_SERVING {
+				code = http.StatusOK
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+	}
+}
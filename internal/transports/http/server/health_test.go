@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/http/server"
+)
+
+// TestHealth verifies /healthz maps the gRPC health status to an HTTP status.
+func TestHealth(t *testing.T) {
+	cases := []struct {
+		name   string
+		conn   *fakeGreeterConn
+		status int
+		body   string
+	}{
+		{"serving", &fakeGreeterConn{health: healthpb.HealthCheckResponse_SERVING}, http.StatusOK, "SERVING"},
+		{"not serving", &fakeGreeterConn{health: healthpb.HealthCheckResponse_NOT_SERVING}, http.StatusServiceUnavailable, "NOT_SERVING"},
+		{"check failed", &fakeGreeterConn{err: status.Error(codes.NotFound, "unknown service")}, http.StatusServiceUnavailable, "UNKNOWN"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := newTestServer(t, c.conn, logger.NewZerologLogger("info", io.Discard))
+
+			rec := httptest.NewRecorder()
+			srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Contains(t, rec.Body.String(), c.body)
+		})
+	}
+}
+
+// TestMetricsHandler verifies /metrics is only served when a handler is given.
+func TestMetricsHandler(t *testing.T) {
+	log := logger.NewZerologLogger("info", io.Discard)
+
+	srv := newTestServer(t, &fakeGreeterConn{}, log)
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	srv, err := server.NewServer(&server.Opts{
+		Config: &config.HTTPServer{Enabled: true, URL: ":0"},
+		Logger: log,
+		Conn:   &fakeGreeterConn{},
+		Metrics: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "app_build_info 1")
+		}),
+	})
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "app_build_info")
+}
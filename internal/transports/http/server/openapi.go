@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// swaggerUIPage renders Swagger UI (loaded from a CDN) for the document at /openapi.json.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>API Docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// openAPIDocument converts the embedded OpenAPI YAML document to JSON.
+func openAPIDocument() ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(proto.OpenAPI, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi document: %w", err)
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode openapi document: %w", err)
+	}
+	return out, nil
+}
+
+func openAPIHandler(doc []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	}
+}
+
+func swaggerUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(swaggerUIPage))
+}
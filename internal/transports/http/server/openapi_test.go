@@ -0,0 +1,54 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/http/server"
+)
+
+// TestOpenAPI_Document verifies the embedded OpenAPI document is served as JSON
+// and describes the annotated routes.
+func TestOpenAPI_Document(t *testing.T) {
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", io.Discard))
+
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	assert.Contains(t, doc["paths"], "/v1/users/{user_id}/hello")
+}
+
+// TestOpenAPI_SwaggerUI verifies the docs page is only served when enabled.
+func TestOpenAPI_SwaggerUI(t *testing.T) {
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", io.Discard))
+
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	srv, err := server.NewServer(&server.Opts{
+		Config: &config.HTTPServer{Enabled: true, URL: ":0", SwaggerUIEnabled: true},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+		Conn:   &fakeGreeterConn{},
+	})
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/openapi.json")
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/compression"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type Opts struct {
+	Config *config.HTTPServer
+	Logger logger.Logger
+	// Conn is used by the gateway handlers to call the gRPC services, usually the
+	// in-process connection of the gRPC server so there's no extra network hop.
+	Conn grpc.ClientConnInterface
+	// Metrics is served at /metrics when set, used when the HTTP transport shares
+	// the gRPC port and there is no separate metrics listener.
+	Metrics     http.Handler
+	Compression *config.Compression // optional
+	TLS         *tls.Config         // optional, see tlsconfig.New
+}
+
+type HTTPServer struct {
+	Server  *http.Server
+	Gateway *runtime.ServeMux
+	Config  *config.HTTPServer
+	TLS     *tls.Config // nil serves plaintext
+	Logger  logger.Logger
+}
+
+func NewServer(opts *Opts) (*HTTPServer, error) {
+	gateway := runtime.NewServeMux(
+		// Use the proto field names (e.g user_id) so the JSON matches what grpcurl shows.
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithErrorHandler(errorHandler(opts.Logger)),
+	)
+
+	if err := registerGateway(context.Background(), gateway, opts.Conn); err != nil {
+		return nil, err
+	}
+
+	doc, err := openAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gateway)
+	mux.HandleFunc("GET /healthz", healthHandler(opts.Conn))
+	mux.HandleFunc("GET /openapi.json", openAPIHandler(doc))
+	if opts.Metrics != nil {
+		mux.Handle("GET /metrics", opts.Metrics)
+	}
+	if opts.Config.SwaggerUIEnabled {
+		mux.HandleFunc("GET /docs", swaggerUIHandler)
+	}
+
+	var handler http.Handler = mux
+	if opts.Compression != nil {
+		handler = compression.Handler(opts.Compression, handler)
+	}
+
+	return &HTTPServer{
+		Server: &http.Server{
+			Handler:           otelhttp.NewHandler(handler, "http.server"),
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		Gateway: gateway,
+		Config:  opts.Config,
+		TLS:     opts.TLS,
+		Logger:  opts.Logger,
+	}, nil
+}
+
+func (s *HTTPServer) ServeListener(listener net.Listener) error {
+	if s.TLS != nil {
+		listener = tls.NewListener(listener, s.TLS)
+	}
+	s.Logger.Info("HTTP server started",
+		logger.Field{Key: "address", Value: listener.Addr().String()},
+		logger.Field{Key: "tls", Value: s.TLS != nil},
+	)
+	if err := s.Server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.Logger.Error("HTTP server failed", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+	return nil
+}
+
+func (s *HTTPServer) Serve() error {
+	url := s.Config.URL
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		s.Logger.Error("Failed to create http listener",
+			logger.Field{Key: "address", Value: url},
+			logger.Field{Key: "error", Value: err.Error()},
+		)
+		return err
+	}
+	return s.ServeListener(listener)
+}
+
+// Shutdown stops accepting new requests and waits for in-flight ones, the
+// connections still open when ctx is done are closed.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	err := s.Server.Shutdown(ctx)
+	if ctx.Err() != nil {
+		_ = s.Server.Close()
+	}
+	return err
+}
@@ -0,0 +1,248 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tests/testutils"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/tlsconfig"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/http/server"
+	helloworld "github.com/sagarmaheshwary/go-microservice-boilerplate/proto/hello_world"
+)
+
+// fakeGreeterConn answers SayHello and health check calls without a gRPC server.
+type fakeGreeterConn struct {
+	err    error
+	health healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (c *fakeGreeterConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if c.err != nil {
+		return c.err
+	}
+	if method == healthpb.Health_Check_FullMethodName {
+		reply.(*healthpb.HealthCheckResponse).Status = c.health
+		return nil
+	}
+	in := args.(*helloworld.SayHelloRequest)
+	out := reply.(*helloworld.SayHelloResponse)
+	out.Message = "Hello, Alice!"
+	out.User = &helloworld.User{Id: in.UserId, Name: "Alice", Email: "alice@example.com"}
+	return nil
+}
+
+func (c *fakeGreeterConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func newTestServer(t *testing.T, conn grpc.ClientConnInterface, log logger.Logger) *server.HTTPServer {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Opts{
+		Config: &config.HTTPServer{Enabled: true, URL: ":0"},
+		Logger: log,
+		Conn:   conn,
+	})
+	require.NoError(t, err)
+	return srv
+}
+
+// TestGateway_SayHello verifies the annotated RPC is exposed as JSON over HTTP.
+func TestGateway_SayHello(t *testing.T) {
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", io.Discard))
+
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/1/hello", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "Hello, Alice!", body["message"])
+	assert.Equal(t, "alice@example.com", body["user"].(map[string]any)["email"])
+}
+
+// TestGateway_ErrorMapping verifies gRPC codes are mapped to HTTP statuses and
+// that server errors are logged.
+func TestGateway_ErrorMapping(t *testing.T) {
+	cases := []struct {
+		code   codes.Code
+		status int
+		logged bool
+	}{
+		{codes.NotFound, http.StatusNotFound, false},
+		{codes.InvalidArgument, http.StatusBadRequest, false},
+		{codes.Unavailable, http.StatusServiceUnavailable, true},
+		{codes.Internal, http.StatusInternalServerError, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.code.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			srv := newTestServer(t, &fakeGreeterConn{err: status.Error(c.code, "boom")}, logger.NewZerologLogger("info", &buf))
+
+			rec := httptest.NewRecorder()
+			srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/1/hello", nil))
+
+			assert.Equal(t, c.status, rec.Code)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, "boom", body["message"])
+			assert.Equal(t, c.logged, bytes.Contains(buf.Bytes(), []byte("HTTP request failed")))
+		})
+	}
+}
+
+// TestGateway_InvalidPathParam verifies malformed path parameters are rejected
+// with 400 before reaching the handler.
+func TestGateway_InvalidPathParam(t *testing.T) {
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", io.Discard))
+
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/abc/hello", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestServer_ServeListenerAndShutdown verifies the server runs on its own listener
+// and shuts down gracefully.
+func TestServer_ServeListenerAndShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", &buf))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ServeListener(lis)
+	}()
+
+	resp, err := http.Get("http://" + lis.Addr().String() + "/v1/users/1/hello")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, srv.Shutdown(context.Background()))
+	require.NoError(t, <-errCh)
+	assert.Contains(t, buf.String(), "HTTP server started")
+}
+
+// blockingConn holds every call until released, to keep requests in flight.
+type blockingConn struct {
+	fakeGreeterConn
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	close(c.started)
+	<-c.release
+	return c.fakeGreeterConn.Invoke(ctx, method, args, reply, opts...)
+}
+
+// TestServer_ShutdownTimeout verifies connections still busy once the drain
+// timeout elapsed are closed instead of keeping the process alive.
+func TestServer_ShutdownTimeout(t *testing.T) {
+	conn := &blockingConn{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(conn.release)
+
+	srv := newTestServer(t, conn, logger.NewZerologLogger("info", io.Discard))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+
+	reqErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + lis.Addr().String() + "/v1/users/1/hello")
+		if err == nil {
+			resp.Body.Close()
+		}
+		reqErr <- err
+	}()
+	<-conn.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, srv.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Error(t, <-reqErr, "the in-flight request must be cut off")
+}
+
+// TestGateway_Compression verifies gateway responses are compressed when the
+// client accepts it.
+func TestGateway_Compression(t *testing.T) {
+	srv, err := server.NewServer(&server.Opts{
+		Config:      &config.HTTPServer{Enabled: true, URL: ":0"},
+		Logger:      logger.NewZerologLogger("info", io.Discard),
+		Conn:        &fakeGreeterConn{},
+		Compression: &config.Compression{Enabled: true, Algorithms: []string{"gzip"}},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/users/1/hello", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	srv.Server.Handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Hello, Alice!")
+}
+
+// TestServer_TLS verifies the server negotiates HTTP/2 and HTTP/1.1 over TLS.
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, pool := testutils.SelfSignedCert(t)
+	tlsConfig, err := tlsconfig.New(&config.TLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2"})
+	require.NoError(t, err)
+
+	srv := newTestServer(t, &fakeGreeterConn{}, logger.NewZerologLogger("info", io.Discard))
+	srv.TLS = tlsConfig
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.ServeListener(lis)
+	}()
+	defer srv.Shutdown(context.Background())
+
+	for _, h2 := range []bool{false, true} {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: pool},
+			ForceAttemptHTTP2: h2,
+		}}
+		resp, err := client.Get("https://" + lis.Addr().String() + "/v1/users/1/hello")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, h2, resp.ProtoMajor == 2)
+	}
+}
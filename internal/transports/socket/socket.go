@@ -0,0 +1,184 @@
+// Package socket creates the listeners the transports serve on from a configured
+// address, which can be a TCP address, a unix domain socket or a socket inherited
+// from systemd socket activation.
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/netaddr"
+)
+
+const (
+	NetworkTCP     = netaddr.NetworkTCP
+	NetworkUnix    = netaddr.NetworkUnix
+	NetworkSystemd = netaddr.NetworkSystemd
+
+	// First file descriptor passed by systemd, after stdin, stdout and stderr.
+	listenFDsStart = 3
+)
+
+type Opts struct {
+	// SocketMode is applied to unix domain socket files, e.g 0660 to let a sidecar
+	// in the same group connect. Zero keeps the mode from the process umask.
+	SocketMode os.FileMode
+	// Inheritor is optional, when set listeners are taken over from a parent process
+	// and handed to the next one during zero-downtime upgrades.
+	Inheritor Inheritor
+}
+
+// Inheritor returns the listener passed by a parent process for network and addr,
+// or creates it with callback. It is implemented by *tableflip.Fds.
+type Inheritor interface {
+	ListenWithCallback(network, addr string, callback func(network, addr string) (net.Listener, error)) (net.Listener, error)
+}
+
+// Listen creates a listener for an address accepted by netaddr.Parse.
+func Listen(address string, opts *Opts) (net.Listener, error) {
+	if opts == nil {
+		opts = &Opts{}
+	}
+
+	network, addr, err := netaddr.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	listen := func(network, addr string) (net.Listener, error) {
+		switch network {
+		case NetworkSystemd:
+			return inheritedListener(addr)
+		case NetworkUnix:
+			return listenUnix(addr, opts.SocketMode)
+		default:
+			return net.Listen(network, addr)
+		}
+	}
+
+	if opts.Inheritor != nil {
+		return opts.Inheritor.ListenWithCallback(network, addr, listen)
+	}
+	return listen(network, addr)
+}
+
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	abstract := strings.HasPrefix(path, "@")
+	if abstract && runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("abstract unix sockets are only supported on linux")
+	}
+	if !abstract {
+		if err := removeStaleSocket(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen(NetworkUnix, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !abstract && mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set mode of %s: %w", path, err)
+		}
+	}
+	return listener, nil
+}
+
+// removeStaleSocket removes a socket file left behind by a process that didn't
+// shut down cleanly. Sockets something is still listening on are left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout(NetworkUnix, path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+type inheritedFile struct {
+	name string
+	file *os.File
+}
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   []*inheritedFile
+)
+
+// inheritedListener returns the first unused listener passed by systemd with the
+// given name, or the first unused one when name is empty.
+func inheritedListener(name string) (net.Listener, error) {
+	inheritOnce.Do(loadInheritedFiles)
+
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+
+	for i, f := range inherited {
+		if f == nil || (name != "" && f.name != name) {
+			continue
+		}
+		inherited[i] = nil
+
+		// FileListener duplicates the descriptor, the inherited one isn't needed anymore.
+		listener, err := net.FileListener(f.file)
+		f.file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherited socket %q is not a listener: %w", f.name, err)
+		}
+		return listener, nil
+	}
+
+	if name == "" {
+		return nil, errors.New("no socket inherited from systemd (LISTEN_FDS), is the service socket activated?")
+	}
+	return nil, fmt.Errorf("no socket named %q inherited from systemd (LISTEN_FDNAMES)", name)
+}
+
+// loadInheritedFiles reads the sd_listen_fds(3) environment. The variables are
+// unset afterwards so they don't leak into child processes.
+func loadInheritedFiles() {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		inherited = append(inherited, &inheritedFile{name: name, file: os.NewFile(uintptr(fd), name)})
+	}
+}
@@ -0,0 +1,153 @@
+package socket_test
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/socket"
+)
+
+// TestListen_TCP verifies plain host:port addresses listen on TCP.
+func TestListen_TCP(t *testing.T) {
+	lis, err := socket.Listen("127.0.0.1:0", nil)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	assert.Equal(t, "tcp", lis.Addr().Network())
+}
+
+// TestListen_Unix verifies a unix socket file is created with the configured mode
+// and removed again when the listener is closed.
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.sock")
+
+	lis, err := socket.Listen("unix://"+path, &socket.Opts{SocketMode: 0600})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+
+	require.NoError(t, lis.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestListen_UnixStaleSocket verifies a socket file left behind by a crashed process
+// is replaced, while one that is still in use is not.
+func TestListen_UnixStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.sock")
+
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	lis, err := socket.Listen("unix://"+path, nil)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	_, err = socket.Listen("unix://"+path, nil)
+	assert.ErrorContains(t, err, "in use")
+}
+
+// TestListen_UnixNotASocket verifies regular files are never removed.
+func TestListen_UnixNotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+	_, err := socket.Listen("unix://"+path, nil)
+	assert.ErrorContains(t, err, "not a socket")
+}
+
+// TestListen_UnixAbstract verifies abstract sockets don't create a file.
+func TestListen_UnixAbstract(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract unix sockets are linux only")
+	}
+
+	name := "go-microservice-boilerplate-test-" + strconv.Itoa(os.Getpid())
+	lis, err := socket.Listen("unix-abstract://"+name, nil)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	conn, err := net.Dial("unix", "@"+name)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+// TestListen_SystemdWithoutSockets verifies a clear error when the process wasn't
+// started through socket activation.
+func TestListen_SystemdWithoutSockets(t *testing.T) {
+	_, err := socket.Listen("systemd://", nil)
+	assert.ErrorContains(t, err, "LISTEN_FDS")
+
+	_, err = socket.Listen("systemd://grpc", nil)
+	assert.ErrorContains(t, err, `"grpc"`)
+}
+
+// TestListen_Systemd verifies a socket passed as in socket activation is picked up
+// by name. The listener is handed to a child process like systemd would.
+func TestListen_Systemd(t *testing.T) {
+	if os.Getenv("SOCKET_TEST_SYSTEMD_CHILD") == "1" {
+		systemdChild()
+		return
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("socket activation is unix only")
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	file, err := lis.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer file.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestListen_Systemd$")
+	cmd.Env = append(os.Environ(), "SOCKET_TEST_SYSTEMD_CHILD=1", "LISTEN_FDS=1", "LISTEN_FDNAMES=grpc")
+	cmd.ExtraFiles = []*os.File{file} // becomes fd 3
+	require.NoError(t, cmd.Start())
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	reply, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "grpc", string(reply))
+	require.NoError(t, cmd.Wait())
+}
+
+// systemdChild accepts one connection on the inherited socket and replies with
+// its name. LISTEN_PID can only be known once the process runs.
+func systemdChild() {
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	lis, err := socket.Listen("systemd://grpc", nil)
+	if err != nil {
+		os.Exit(2)
+	}
+	defer lis.Close()
+
+	conn, err := lis.Accept()
+	if err != nil {
+		os.Exit(3)
+	}
+	_, _ = conn.Write([]byte("grpc"))
+	conn.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package upgrade
+
+import (
+	"os"
+	"syscall"
+)
+
+// SIGHUP reloads the config, see config.Watcher.
+var upgradeSignals = []os.Signal{syscall.SIGUSR2}
@@ -0,0 +1,6 @@
+package upgrade
+
+import "os"
+
+// Upgrades aren't supported on windows, tableflip.New fails there.
+var upgradeSignals []os.Signal
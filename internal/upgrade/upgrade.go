@@ -0,0 +1,128 @@
+// Package upgrade replaces the running binary without closing its listeners: the
+// new process inherits the listening sockets, and the old one drains and exits
+// once the new one is ready.
+package upgrade
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/cloudflare/tableflip"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/socket"
+)
+
+type Opts struct {
+	Config *config.Upgrade
+	Logger logger.Logger
+	// Dotenv are the variables set by the .env files, see config.Config.Dotenv.
+	Dotenv []string
+}
+
+// Upgrader wraps tableflip, when upgrades are disabled it does nothing so callers
+// don't need to check the config.
+type Upgrader struct {
+	upg    *tableflip.Upgrader // nil when disabled
+	exit   chan struct{}
+	dotenv []string
+	Logger logger.Logger
+}
+
+func New(opts *Opts) (*Upgrader, error) {
+	u := &Upgrader{Logger: opts.Logger, dotenv: opts.Dotenv}
+	if !opts.Config.Enabled {
+		u.exit = make(chan struct{}) // never closed
+		return u, nil
+	}
+
+	upg, err := tableflip.New(tableflip.Options{
+		UpgradeTimeout: opts.Config.Timeout,
+		PIDFile:        opts.Config.PIDFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.upg = upg
+
+	if upg.HasParent() {
+		u.Logger.Info("Started by an upgrade, inheriting listeners from the parent process")
+	}
+	return u, nil
+}
+
+// Inheritor is passed to socket.Listen so listeners are inherited from the parent
+// and handed over to the next process, it's nil when upgrades are disabled.
+func (u *Upgrader) Inheritor() socket.Inheritor {
+	if u.upg == nil {
+		return nil
+	}
+	return u.upg.Fds
+}
+
+// Ready tells the parent process that this one is serving, the parent then exits.
+// It must be called once every listener has been created, unused inherited ones are closed.
+func (u *Upgrader) Ready() error {
+	if u.upg == nil {
+		return nil
+	}
+	return u.upg.Ready()
+}
+
+// Exit is closed when a new process took over and this one should drain and exit.
+func (u *Upgrader) Exit() <-chan struct{} {
+	if u.upg == nil {
+		return u.exit
+	}
+	return u.upg.Exit()
+}
+
+// Upgrade starts the new binary and waits until it's ready or failed.
+func (u *Upgrader) Upgrade() error {
+	if u.upg == nil {
+		return nil
+	}
+
+	// The new process inherits the environment, where the variables of the .env
+	// files would take precedence over the files and hide their edits.
+	for _, key := range u.dotenv {
+		_ = os.Unsetenv(key)
+	}
+
+	u.Logger.Info("Upgrade requested, starting new process")
+	if err := u.upg.Upgrade(); err != nil {
+		u.Logger.Error("Upgrade failed, keep serving", logger.Field{Key: "error", Value: err.Error()})
+		return err
+	}
+	u.Logger.Info("Upgrade completed, new process is ready")
+	return nil
+}
+
+// HandleSignals triggers an upgrade on SIGUSR2 until ctx is done.
+func (u *Upgrader) HandleSignals(ctx context.Context) {
+	if u.upg == nil || len(upgradeSignals) == 0 {
+		return
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, upgradeSignals...)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-sig:
+			_ = u.Upgrade()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop prevents further upgrades. When no upgrade happened the process is shutting
+// down for good and the unix sockets it created are removed.
+func (u *Upgrader) Stop() {
+	if u.upg != nil {
+		u.upg.Stop()
+	}
+}
@@ -0,0 +1,60 @@
+package upgrade_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/config"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/logger"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/transports/socket"
+	"github.com/sagarmaheshwary/go-microservice-boilerplate/internal/upgrade"
+)
+
+// TestUpgrader_Disabled verifies a disabled upgrader is a no-op.
+func TestUpgrader_Disabled(t *testing.T) {
+	u, err := upgrade.New(&upgrade.Opts{
+		Config: &config.Upgrade{Enabled: false, Timeout: time.Minute},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, u.Inheritor())
+	assert.NoError(t, u.Ready())
+	assert.NoError(t, u.Upgrade())
+
+	select {
+	case <-u.Exit():
+		t.Fatal("exit channel must not be closed")
+	default:
+	}
+	u.Stop()
+}
+
+// TestUpgrader_Enabled verifies listeners are registered for the handover and the
+// process keeps running until an upgrade happens.
+func TestUpgrader_Enabled(t *testing.T) {
+	u, err := upgrade.New(&upgrade.Opts{
+		Config: &config.Upgrade{Enabled: true, Timeout: time.Minute},
+		Logger: logger.NewZerologLogger("info", io.Discard),
+	})
+	require.NoError(t, err)
+	defer u.Stop()
+
+	require.NotNil(t, u.Inheritor())
+
+	lis, err := socket.Listen("127.0.0.1:0", &socket.Opts{Inheritor: u.Inheritor()})
+	require.NoError(t, err)
+	defer lis.Close()
+
+	require.NoError(t, u.Ready())
+
+	select {
+	case <-u.Exit():
+		t.Fatal("exit channel must only be closed after an upgrade")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
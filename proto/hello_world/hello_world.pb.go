@@ -7,6 +7,7 @@
 package hello_world
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -181,7 +182,7 @@ var File_proto_hello_world_hello_world_proto protoreflect.FileDescriptor
 
 const file_proto_hello_world_hello_world_proto_rawDesc = "" +
 	"\n" +
-	"#proto/hello_world/hello_world.proto\x12\vhello_world\"*\n" +
+	"#proto/hello_world/hello_world.proto\x12\vhello_world\x1a\x1cgoogle/api/annotations.proto\"*\n" +
 	"\x0fSayHelloRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\"S\n" +
 	"\x10SayHelloResponse\x12\x18\n" +
@@ -190,9 +191,9 @@ const file_proto_hello_world_hello_world_proto_rawDesc = "" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email2R\n" +
-	"\aGreeter\x12G\n" +
-	"\bSayHello\x12\x1c.hello_world.SayHelloRequest\x1a\x1d.hello_world.SayHelloResponseBJZHgithub.com/SagarMaheshwary/go-microservice-boilerplate/proto/hello_worldb\x06proto3"
+	"\x05email\x18\x03 \x01(\tR\x05email2u\n" +
+	"\aGreeter\x12j\n" +
+	"\bSayHello\x12\x1c.hello_world.SayHelloRequest\x1a\x1d.hello_world.SayHelloResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/users/{user_id}/helloBJZHgithub.com/SagarMaheshwary/go-microservice-boilerplate/proto/hello_worldb\x06proto3"
 
 var (
 	file_proto_hello_world_hello_world_proto_rawDescOnce sync.Once
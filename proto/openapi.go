@@ -0,0 +1,9 @@
+package proto
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI v3 document generated from the google.api.http annotations
+// of the services in this directory (see `make proto-gen`).
+//
+//go:embed openapi.yaml
+var OpenAPI []byte